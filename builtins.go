@@ -0,0 +1,68 @@
+package synta
+
+import (
+	"regexp"
+	"slices"
+)
+
+// builtinDefinitions are the definitions available without declaring them
+// when a spec is parsed with Options.Builtins
+var builtinDefinitions = map[Identifier]string{
+	"word":  "[a-z]+",
+	"num":   "[0-9]+",
+	"digit": "[0-9]",
+	"slug":  "[a-z0-9]+(?:_[a-z0-9]+)*",
+	"year":  "[0-9]{4}",
+	"date":  "[0-9]{4}[0-9]{2}[0-9]{2}",
+	"ext":   "[a-z0-9]+",
+}
+
+// StandardDefinitions returns the builtin definitions, such as `digit`,
+// `year` or `slug`, compiled and ready to be added to the definitions of a
+// spec. Parsing with Options.Builtins provides them automatically.
+func StandardDefinitions() (definitions map[Identifier]Definition) {
+	definitions = map[Identifier]Definition{}
+	for id, pattern := range builtinDefinitions {
+		definitions[id] = Definition{Regexp: regexp.MustCompile(pattern), Pattern: pattern}
+	}
+	return
+}
+
+// keywords are the words used by directives, which should not be used as
+// identifiers to keep directives readable
+var keywords = []Identifier{"require", "unless", "key", "separator"}
+
+// reservation tells whether an identifier is reserved, being a keyword or
+// the name of a builtin definition, and describes why
+func reservation(id Identifier) (reason string, reserved bool) {
+	if slices.Contains(keywords, id) {
+		return "is a reserved keyword", true
+	}
+	if _, ok := builtinDefinitions[id]; ok {
+		return "shadows the builtin definition", true
+	}
+	return "", false
+}
+
+// provideBuiltins adds to the spec the builtin definitions of the identifiers
+// used by the filenames which the spec does not define itself
+func provideBuiltins(s *Synta, opts Options) {
+	used := []Identifier{}
+	for _, f := range s.filenames() {
+		used = append(used, f.identifiers()...)
+	}
+	for _, id := range used {
+		pattern, isBuiltin := builtinDefinitions[id]
+		if _, defined := s.Definitions[id]; defined || !isBuiltin {
+			continue
+		}
+
+		def := Definition{Pattern: pattern}
+		if opts.LazyCompile {
+			def.lazy = &lazyRegexp{}
+		} else {
+			def.Regexp = regexp.MustCompile(pattern)
+		}
+		s.Definitions[id] = def
+	}
+}
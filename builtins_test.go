@@ -0,0 +1,39 @@
+package synta
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStandardDefinitions(t *testing.T) {
+	definitions := StandardDefinitions()
+	assert.Equal(t, "[0-9]", definitions["digit"].Source())
+	assert.True(t, definitions["slug"].Regexp.MatchString("analisi_1"))
+
+	input := "name = [a-z]+\next = pdf\n> name-digit.ext"
+	_, err := ParseSynta(input)
+	assert.EqualError(t, err, "missing definition for `digit`")
+
+	synta, err := ParseSyntaWithOptions(input, Options{Builtins: true})
+	assert.Nil(t, err)
+	matches, err := synta.Match("lesson-3.pdf")
+	assert.Nil(t, err)
+	assert.True(t, matches)
+
+	// the standard definitions can also be added by hand
+	slug := Identifier("slug")
+	synta = Synta{Definitions: StandardDefinitions(), Filename: Filename{Segments: []Segment{{Kind: SegmentTypeIdentifier, Value: &slug}}, Extension: "ext"}}
+	matches, err = synta.Match("analisi_1.pdf")
+	assert.Nil(t, err)
+	assert.True(t, matches)
+}
+
+func TestBuiltinsWithAlternatives(t *testing.T) {
+	synta, err := ParseSyntaWithOptions("> word.ext\n> word-year.ext", Options{Builtins: true, MultipleFilenames: true})
+	assert.Nil(t, err)
+	assert.Contains(t, synta.Definitions, Identifier("year"))
+	matches, err := synta.Match("lesson-2024.pdf")
+	assert.Nil(t, err)
+	assert.True(t, matches)
+}
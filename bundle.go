@@ -0,0 +1,66 @@
+package synta
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// maxEnumeratedExtensions bounds how many extensions are enumerated from a
+// single extension regexp before giving up and treating it as unbounded
+const maxEnumeratedExtensions = 256
+
+// DetectExtensionOverlaps reports every pair of specs whose extension
+// definitions could both match the same extension, which makes classifying a
+// file among the specs ambiguous. The comparison is conservative: when neither
+// extension regexp matches a finite set of strings, the pair is reported.
+func DetectExtensionOverlaps(specs map[string]Synta) (overlaps []string) {
+	names := []string{}
+	for name := range specs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for i, a := range names {
+		for _, b := range names[i+1:] {
+			shared, overlap := extensionOverlap(specs[a], specs[b])
+			if !overlap {
+				continue
+			}
+			if shared != "" {
+				overlaps = append(overlaps, fmt.Sprintf("`%s` and `%s` can both match extension `%s`", a, b, shared))
+			} else {
+				overlaps = append(overlaps, fmt.Sprintf("`%s` and `%s` may match the same extension", a, b))
+			}
+		}
+	}
+	return
+}
+
+// extensionOverlap tells if the extensions of two specs may overlap, along
+// with a shared extension when one can be found
+func extensionOverlap(a, b Synta) (shared string, overlap bool) {
+	patternA := a.extensionSource()
+	patternB := b.extensionSource()
+
+	if extensions, ok := finiteStrings(patternA, maxEnumeratedExtensions); ok {
+		return firstMatching(extensions, patternB)
+	}
+	if extensions, ok := finiteStrings(patternB, maxEnumeratedExtensions); ok {
+		return firstMatching(extensions, patternA)
+	}
+	return "", true
+}
+
+func firstMatching(candidates []string, pattern string) (match string, found bool) {
+	expr, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return "", true
+	}
+	for _, candidate := range candidates {
+		if expr.MatchString(candidate) {
+			return candidate, true
+		}
+	}
+	return "", false
+}
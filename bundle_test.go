@@ -0,0 +1,55 @@
+package synta
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectExtensionOverlaps(t *testing.T) {
+	specs := map[string]Synta{
+		"slides": MustSynta(`name = [a-z]+
+ext = pdf|pptx
+> name.ext`),
+		"notes": MustSynta(`name = [a-z]+
+ext = md|pdf
+> name.ext`),
+		"code": MustSynta(`name = [a-z]+
+ext = go|c
+> name.ext`),
+	}
+
+	assert.Equal(t, []string{"`notes` and `slides` can both match extension `pdf`"}, DetectExtensionOverlaps(specs))
+}
+
+func TestDetectExtensionOverlapsWithUnboundedExtensions(t *testing.T) {
+	specs := map[string]Synta{
+		"a": MustSynta(`name = [a-z]+
+ext = [a-z]+
+> name.ext`),
+		"b": MustSynta(`name = [a-z]+
+ext = [a-z0-9]+
+> name.ext`),
+		"c": MustSynta(`name = [a-z]+
+ext = [0-9]
+> name.ext`),
+	}
+
+	assert.Equal(t, []string{
+		"`a` and `b` may match the same extension",
+		"`b` and `c` can both match extension `0`",
+	}, DetectExtensionOverlaps(specs))
+}
+
+func TestDetectExtensionOverlapsWithoutOverlaps(t *testing.T) {
+	specs := map[string]Synta{
+		"a": MustSynta(`name = [a-z]+
+ext = pdf
+> name.ext`),
+		"b": MustSynta(`name = [a-z]+
+ext = txt
+> name.ext`),
+	}
+
+	assert.Empty(t, DetectExtensionOverlaps(specs))
+}
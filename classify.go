@@ -0,0 +1,160 @@
+package synta
+
+import (
+	"slices"
+	"sort"
+)
+
+// ClassifyDefinitions partitions the definitions by how the filename
+// references them: required ones appear outside of any optional segment, the
+// extension included, optional ones only appear inside optional segments or
+// alternations, of extensions too, and unused ones do not appear at all.
+// Repeated segments count as required. Backreferences count as references of
+// their identifier. With Alternatives, the required definitions are the ones
+// every filename requires, while the others they reference are optional. Each
+// slice is sorted alphabetically.
+func (s Synta) ClassifyDefinitions() (required, optional, unused []Identifier) {
+	isRequired, isOptional := s.classifyIdentifiers()
+	for id := range s.Definitions {
+		switch {
+		case isRequired[id]:
+			required = append(required, id)
+		case isOptional[id]:
+			optional = append(optional, id)
+		default:
+			unused = append(unused, id)
+		}
+	}
+	for _, ids := range [][]Identifier{required, optional, unused} {
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	}
+	return
+}
+
+// classifyIdentifiers tells which identifiers every filename requires and
+// which ones are only referenced by some of them, or only optionally
+func (s Synta) classifyIdentifiers() (isRequired, isOptional map[Identifier]bool) {
+	isRequired, isOptional = s.Filename.classifyIdentifiers()
+	for _, f := range s.Alternatives {
+		required, optional := f.classifyIdentifiers()
+		for id := range isRequired {
+			if !required[id] {
+				delete(isRequired, id)
+				isOptional[id] = true
+			}
+		}
+		for id := range required {
+			if !isRequired[id] {
+				isOptional[id] = true
+			}
+		}
+		for id := range optional {
+			isOptional[id] = true
+		}
+	}
+	return
+}
+
+// classifyIdentifiers tells which identifiers the filename requires, its
+// extension included, and which ones it references only optionally
+func (f Filename) classifyIdentifiers() (isRequired, isOptional map[Identifier]bool) {
+	isRequired = map[Identifier]bool{}
+	isOptional = map[Identifier]bool{}
+	if len(f.Extensions) == 0 && f.Extension != "" {
+		isRequired[f.Extension] = true
+	}
+	for _, ext := range f.Extensions {
+		isOptional[ext] = true
+	}
+	classifySegments(f.Segments, isRequired, isOptional)
+	return
+}
+
+func classifySegments(segments []Segment, isRequired, isOptional map[Identifier]bool) {
+	for _, seg := range segments {
+		switch seg.Kind {
+		case SegmentTypeIdentifier, SegmentTypeBackreference:
+			isRequired[*seg.Value] = true
+		case SegmentTypeRepeat:
+			classifySegments(seg.Subsegments, isRequired, isOptional)
+		case SegmentTypeOptional, SegmentTypeAlternation:
+			for _, id := range getAllIdentifiers(seg.Subsegments) {
+				isOptional[id] = true
+			}
+		}
+	}
+}
+
+// UnusedDefinitions returns the definitions the filename and its
+// alternatives do not reference, neither in their segments nor in their
+// extensions, in declaration order. Unlike Clear, the spec is left untouched,
+// so that linters can report them.
+func (s Synta) UnusedDefinitions() (unused []Identifier) {
+	used := map[Identifier]bool{}
+	for _, id := range s.AllIdentifiers() {
+		used[id] = true
+	}
+
+	unused = []Identifier{}
+	for _, id := range s.declarationOrder() {
+		if !used[id] {
+			unused = append(unused, id)
+		}
+	}
+	return
+}
+
+// RequiredIdentifiers returns the identifiers every filename contains, in
+// order of appearance and without duplicates, followed by the extension. As
+// with ClassifyDefinitions, the identifiers appearing only inside optional
+// segments or alternations, of extensions too, or missing from some of the
+// Alternatives, are left out.
+func (s Synta) RequiredIdentifiers() (required []Identifier) {
+	isRequired, _ := s.classifyIdentifiers()
+	ids := getRequiredIdentifiers(s.Filename.Segments)
+	if len(s.Filename.Extensions) == 0 && s.Filename.Extension != "" {
+		ids = append(ids, s.Filename.Extension)
+	}
+
+	required = []Identifier{}
+	for _, id := range ids {
+		if isRequired[id] && !slices.Contains(required, id) {
+			required = append(required, id)
+		}
+	}
+	return
+}
+
+// AllIdentifiers returns every identifier the filename references, inside
+// optional segments and alternations too, followed by the extensions, in order
+// of first appearance and without duplicates. The identifiers of the
+// Alternatives follow the ones of the filename.
+func (s Synta) AllIdentifiers() (all []Identifier) {
+	all = []Identifier{}
+	for _, f := range s.filenames() {
+		for _, id := range f.identifiers() {
+			if !slices.Contains(all, id) {
+				all = append(all, id)
+			}
+		}
+	}
+	return
+}
+
+// MissingDefinitions returns every identifier the filename and its
+// alternatives reference, nested optionals and extensions included, which has
+// no definition, in order of appearance. Parsing reports only the first of
+// them.
+func (s Synta) MissingDefinitions() (missing []Identifier) {
+	required := []Identifier{}
+	for _, f := range s.filenames() {
+		required = append(required, getRequiredIdentifiers(f.Segments)...)
+		required = append(required, f.extensions()...)
+	}
+	for _, id := range required {
+		if _, ok := s.Definitions[id]; !ok && !slices.Contains(missing, id) {
+			missing = append(missing, id)
+		}
+	}
+	return
+}
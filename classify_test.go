@@ -0,0 +1,116 @@
+package synta
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClassifyDefinitions(t *testing.T) {
+	synta := MustSynta(`name = [a-z]+
+year = [0-9]{4}
+tag = [a-z]+
+author = [a-z]+
+draft = [a-z]+
+ext = pdf
+> name(-tag)?(-author(-year)?)?-year.ext`)
+
+	required, optional, unused := synta.ClassifyDefinitions()
+	assert.Equal(t, []Identifier{"ext", "name", "year"}, required)
+	assert.Equal(t, []Identifier{"author", "tag"}, optional)
+	assert.Equal(t, []Identifier{"draft"}, unused)
+
+	synta = MustSynta(`name = [a-z]+
+> name.name`)
+	required, optional, unused = synta.ClassifyDefinitions()
+	assert.Equal(t, []Identifier{"name"}, required)
+	assert.Nil(t, optional)
+	assert.Nil(t, unused)
+}
+
+func TestUnusedDefinitions(t *testing.T) {
+	synta := MustSynta(`year = [0-9]{4}
+name = [a-z]+
+draft = [a-z]+
+tag = [a-z]+
+author = [a-z]+
+ext = pdf
+> name(-(tag|year))?.ext`)
+	assert.Equal(t, []Identifier{"draft", "author"}, synta.UnusedDefinitions())
+
+	synta = MustSynta(`name = [a-z]+
+> name.name`)
+	assert.Equal(t, []Identifier{}, synta.UnusedDefinitions())
+}
+
+func TestMissingDefinitions(t *testing.T) {
+	synta := MustSynta(`name = [a-z]+
+year = [0-9]{4}
+tag = [a-z]+
+author = [a-z]+
+ext = pdf
+> name-year(-tag(-author)?)?-year.ext`)
+	assert.Nil(t, synta.MissingDefinitions())
+
+	delete(synta.Definitions, "year")
+	delete(synta.Definitions, "author")
+	delete(synta.Definitions, "ext")
+	assert.Equal(t, []Identifier{"year", "author", "ext"}, synta.MissingDefinitions())
+}
+
+func TestRequiredIdentifiers(t *testing.T) {
+	synta := MustSynta(`name = [a-z]+
+year = [0-9]{4}
+tag = [a-z]+
+author = [a-z]+
+kind = [a-z]+
+ext = pdf
+> name-year(-tag(-author)?)?-(kind|{x})-=year.ext`)
+	assert.Equal(t, []Identifier{"name", "year", "ext"}, synta.RequiredIdentifiers())
+
+	synta = MustSynta(`tag = [a-z]+
+jpg = jpg
+png = png
+> (-tag)?-{[0-9]+}.(jpg|png)`)
+	assert.Equal(t, []Identifier{}, synta.RequiredIdentifiers())
+}
+
+func TestAllIdentifiers(t *testing.T) {
+	synta := MustSynta(`name = [a-z]+
+year = [0-9]{4}
+tag = [a-z]+
+author = [a-z]+
+kind = [a-z]+
+ext = pdf
+> name-year(-tag(-author)?)?-(kind|{x})-=year.ext`)
+	assert.Equal(t, []Identifier{"name", "year", "tag", "author", "kind", "ext"}, synta.AllIdentifiers())
+
+	synta = MustSynta(`tag = [a-z]+
+jpg = jpg
+png = png
+> (-tag)?-{[0-9]+}.(jpg|png)`)
+	assert.Equal(t, []Identifier{"tag", "jpg", "png"}, synta.AllIdentifiers())
+}
+
+func TestClassifyDefinitionsWithAlternatives(t *testing.T) {
+	synta, err := ParseSyntaWithOptions(`course = [a-z]+
+year = [0-9]{4}
+tag = [a-z]+
+author = [a-z]+
+draft = [a-z]+
+ext = pdf
+> course-year(-author)?.ext
+> course-tag.ext`, Options{MultipleFilenames: true})
+	assert.Nil(t, err)
+
+	required, optional, unused := synta.ClassifyDefinitions()
+	assert.Equal(t, []Identifier{"course", "ext"}, required)
+	assert.Equal(t, []Identifier{"author", "tag", "year"}, optional)
+	assert.Equal(t, []Identifier{"draft"}, unused)
+	assert.Equal(t, []Identifier{"course", "ext"}, synta.RequiredIdentifiers())
+	assert.Equal(t, []Identifier{"course", "year", "author", "ext", "tag"}, synta.AllIdentifiers())
+	assert.Equal(t, []Identifier{"draft"}, synta.UnusedDefinitions())
+
+	delete(synta.Definitions, "tag")
+	assert.Equal(t, []Identifier{"tag"}, synta.MissingDefinitions())
+}
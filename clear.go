@@ -1,20 +1,33 @@
 package synta
 
-// Clear returns a new Synta structure without any unused definitions
+// Clear returns a new Synta structure without any unused definitions. Its
+// nodes are the ones of the used definitions and of the filename, in their
+// original order, so that it serializes like the original spec.
 func Clear(synta Synta) (s Synta) {
 	s.Filename = synta.Filename
+	s.Alternatives = synta.Alternatives
+	s.Constraints = synta.Constraints
+	s.LowercaseInput = synta.LowercaseInput
+	s.MatchStrategy = synta.MatchStrategy
+	s.Separator = synta.Separator
+	s.key = synta.key
 	s.Definitions = map[Identifier]Definition{}
-	s.Definitions[s.Filename.Extension] = synta.Definitions[s.Filename.Extension]
-	clearSegments(synta, s, s.Filename.Segments)
-	return
-}
+	for _, filename := range s.filenames() {
+		for _, ext := range filename.extensions() {
+			s.Definitions[ext] = synta.Definitions[ext]
+		}
+		WalkSegments(filename.Segments, func(segment Segment) bool {
+			if segment.Kind == SegmentTypeIdentifier || segment.Kind == SegmentTypeBackreference {
+				s.Definitions[*segment.Value] = synta.Definitions[*segment.Value]
+			}
+			return true
+		})
+	}
 
-func clearSegments(synta Synta, s Synta, segments []Segment) {
-	for _, segment := range segments {
-		if segment.Kind == SegmentTypeIdentifier {
-			s.Definitions[*segment.Value] = synta.Definitions[*segment.Value]
-		} else if segment.Kind == SegmentTypeOptional {
-			clearSegments(synta, s, segment.Subsegments)
+	for _, node := range synta.Nodes {
+		if _, used := s.Definitions[node.Identifier]; used || node.Type == NodeTypeFilename {
+			s.Nodes = append(s.Nodes, node)
 		}
 	}
+	return
 }
@@ -39,3 +39,29 @@ needless = c|d
 	}
 	checkDefinitions(t, synta.Definitions, exp)
 }
+
+func TestClearKeepsNodes(t *testing.T) {
+	synta := MustSynta(`; a test comment
+test = a|b
+needless = c|d
+> test.ext
+; the extension
+ext = pdf`)
+	cleared := Clear(synta)
+
+	assert.Len(t, cleared.Nodes, 3)
+	for _, node := range cleared.Nodes {
+		if node.Type == NodeTypeDefinition {
+			assert.Equal(t, cleared.Definitions[node.Identifier].Comments, node.Definition.Comments)
+		} else {
+			assert.Equal(t, cleared.Filename.String(), node.Filename.String())
+		}
+	}
+	assert.Equal(t, `; a test comment
+test = a|b
+; the extension
+ext = pdf
+
+> test.ext
+`, cleared.String())
+}
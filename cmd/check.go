@@ -1,47 +1,157 @@
 package main
 
 import (
+	"bufio"
 	"context"
 	"flag"
 	"fmt"
+	"io"
+	"os"
+	"sort"
 	"strings"
 
 	"github.com/cartabinaria/synta"
 	"github.com/google/subcommands"
 )
 
-type checkCommand struct{}
+type checkCommand struct {
+	json bool
+	// stdin is read when a filename is "-", os.Stdin when nil
+	stdin io.Reader
+	// stdout receives the output once the synta file is parsed, os.Stdout
+	// when nil
+	stdout io.Writer
+}
+
+// checkSummary is printed with -json once the synta file is parsed
+type checkSummary struct {
+	Definitions []synta.Identifier `json:"definitions"`
+	Filename    string             `json:"filename"`
+	Unused      []synta.Identifier `json:"unused,omitempty"`
+	Filenames   map[string]bool    `json:"filenames,omitempty"`
+}
 
 func (*checkCommand) Name() string     { return "check" }
 func (*checkCommand) Synopsis() string { return "Checks if a synta file has a corrent syntax." }
 func (*checkCommand) Usage() string {
-	return `check <file>:
-  Checks if a synta file has a corrent syntax.
+	return `check <file> [<filename>...]:
+  Checks if a synta file has a corrent syntax. When filenames are given, each
+  of them is also checked against the synta file. With "-", filenames are
+  read from the standard input, one per line. With -json, a syntax error is
+  printed as {"line":N,"pos":M,"message":"..."}, otherwise a summary of the
+  definitions, the filename and the checked filenames is printed.
 `
 }
 
-func (p *checkCommand) SetFlags(f *flag.FlagSet) {}
+func (p *checkCommand) SetFlags(f *flag.FlagSet) {
+	f.BoolVar(&p.json, "json", false, "print the result as JSON, for editors")
+}
 
 func (p *checkCommand) Execute(_ context.Context, f *flag.FlagSet, _ ...interface{}) subcommands.ExitStatus {
+	if p.json && f.Arg(0) != "" {
+		return p.executeJSON(f)
+	}
 	syntaFilePtr, status := parseFile(p, f)
 	if status != subcommands.ExitSuccess {
 		return status
 	}
 
+	stdout := p.stdout
+	if stdout == nil {
+		stdout = os.Stdout
+	}
 	syntaFile := *syntaFilePtr
-	clearedSyntaFile := synta.Clear(syntaFile)
+	if unused := syntaFile.UnusedDefinitions(); len(unused) > 0 {
+		names := []string{}
+		for _, id := range unused {
+			names = append(names, string(id))
+		}
+		fmt.Fprintf(stdout, "Your Synta file contains unused definitions: %s\n", strings.Join(names, ", "))
+		status = subcommands.ExitFailure
+	}
 
-	if len(syntaFile.Definitions) > len(clearedSyntaFile.Definitions) {
-		unusedDefintions := []string{}
-		for k := range syntaFile.Definitions {
-			if _, notRemoved := clearedSyntaFile.Definitions[k]; !notRemoved {
-				unusedDefintions = append(unusedDefintions, string(k))
-			}
+	filenames, err := p.filenames(f.Args()[1:])
+	if err != nil {
+		fmt.Fprintf(stdout, "Error while reading the filenames: %v\n", err)
+		return subcommands.ExitFailure
+	}
+	for _, filename := range filenames {
+		matches, err := syntaFile.Match(filename)
+		if err != nil {
+			fmt.Fprintf(stdout, "Error while matching the filenames: %v\n", err)
+			return subcommands.ExitFailure
 		}
+		if matches {
+			fmt.Fprintf(stdout, "%s: ok\n", filename)
+		} else {
+			fmt.Fprintf(stdout, "%s: does not match\n", filename)
+			status = subcommands.ExitFailure
+		}
+	}
+	return status
+}
+
+// executeJSON works like Execute, printing its result as JSON
+func (p *checkCommand) executeJSON(f *flag.FlagSet) subcommands.ExitStatus {
+	stdout := p.stdout
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+	syntaFilePtr, status := parsePathJSON(f.Arg(0), stdout)
+	if status != subcommands.ExitSuccess {
+		return status
+	}
 
-		fmt.Printf("Your Synta file contains unused definitions: %s\n", strings.Join(unusedDefintions, ", "))
+	syntaFile := *syntaFilePtr
+	summary := checkSummary{Filename: syntaFile.Filename.String(), Unused: syntaFile.UnusedDefinitions()}
+	for id := range syntaFile.Definitions {
+		summary.Definitions = append(summary.Definitions, id)
+	}
+	sort.Slice(summary.Definitions, func(i, j int) bool { return summary.Definitions[i] < summary.Definitions[j] })
+	if len(summary.Unused) > 0 {
+		status = subcommands.ExitFailure
+	}
+
+	filenames, err := p.filenames(f.Args()[1:])
+	if err != nil {
+		printJSON(stdout, diagnostic{Message: fmt.Sprintf("Error while reading the filenames: %v", err)})
 		return subcommands.ExitFailure
 	}
+	for _, filename := range filenames {
+		if summary.Filenames == nil {
+			summary.Filenames = map[string]bool{}
+		}
+		matches, err := syntaFile.Match(filename)
+		summary.Filenames[filename] = matches && err == nil
+		if !summary.Filenames[filename] {
+			status = subcommands.ExitFailure
+		}
+	}
+	printJSON(stdout, summary)
+	return status
+}
+
+// filenames expands the "-" arguments into the lines of the standard input
+func (p *checkCommand) filenames(args []string) (filenames []string, err error) {
+	for _, arg := range args {
+		if arg != "-" {
+			filenames = append(filenames, arg)
+			continue
+		}
 
-	return subcommands.ExitSuccess
+		stdin := p.stdin
+		if stdin == nil {
+			stdin = os.Stdin
+		}
+		scanner := bufio.NewScanner(stdin)
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); line != "" {
+				filenames = append(filenames, line)
+			}
+		}
+		if err = scanner.Err(); err != nil {
+			return
+		}
+	}
+	return
 }
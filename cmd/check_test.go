@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/subcommands"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheck(t *testing.T) {
+	spec := filepath.Join(t.TempDir(), "spec.synta")
+	err := os.WriteFile(spec, []byte(`type = lesson
+number = [0-9]{2}
+title = [a-z]+
+ext = pdf
+> type-number(-title)?.ext
+`), 0644)
+	assert.Nil(t, err)
+
+	check := func(stdin string, args ...string) subcommands.ExitStatus {
+		p := &checkCommand{stdin: strings.NewReader(stdin)}
+		f := flag.NewFlagSet("check", flag.ContinueOnError)
+		p.SetFlags(f)
+		assert.Nil(t, f.Parse(append([]string{spec}, args...)))
+		return p.Execute(context.Background(), f)
+	}
+
+	assert.Equal(t, subcommands.ExitSuccess, check(""))
+	assert.Equal(t, subcommands.ExitSuccess, check("", "lesson-01.pdf", "lesson-01-intro.pdf"))
+	assert.Equal(t, subcommands.ExitFailure, check("", "lesson-01.pdf", "lesson-1.pdf"))
+	assert.Equal(t, subcommands.ExitSuccess, check("lesson-02.pdf\n\nlesson-03-end.pdf\n", "lesson-01.pdf", "-"))
+	assert.Equal(t, subcommands.ExitFailure, check("lesson-02.pdf\nnotes.txt\n", "-"))
+}
+
+func TestCheckJSON(t *testing.T) {
+	dir := t.TempDir()
+	spec := filepath.Join(dir, "spec.synta")
+	assert.Nil(t, os.WriteFile(spec, []byte("number = [0-9]{2}\nunused = [a-z]+\next = pdf\n> number.ext\n"), 0644))
+	broken := filepath.Join(dir, "broken.synta")
+	assert.Nil(t, os.WriteFile(broken, []byte("number = [0-9]{2}\next = pdf\n> number_x.ext\n"), 0644))
+
+	check := func(args ...string) (subcommands.ExitStatus, map[string]any) {
+		var stdout bytes.Buffer
+		p := &checkCommand{stdout: &stdout}
+		f := flag.NewFlagSet("check", flag.ContinueOnError)
+		p.SetFlags(f)
+		assert.Nil(t, f.Parse(append([]string{"-json"}, args...)))
+		status := p.Execute(context.Background(), f)
+
+		var output map[string]any
+		assert.Nil(t, json.Unmarshal(stdout.Bytes(), &output))
+		return status, output
+	}
+
+	status, output := check(broken)
+	assert.Equal(t, subcommands.ExitFailure, status)
+	assert.Equal(t, float64(3), output["line"])
+	assert.Equal(t, float64(8), output["pos"])
+	assert.Contains(t, output["message"], "Invalid char at column 7")
+
+	status, output = check(spec, "01.pdf", "1.pdf")
+	assert.Equal(t, subcommands.ExitFailure, status)
+	assert.Equal(t, map[string]any{
+		"definitions": []any{"ext", "number", "unused"},
+		"filename":    "number.ext",
+		"unused":      []any{"unused"},
+		"filenames":   map[string]any{"01.pdf": true, "1.pdf": false},
+	}, output)
+}
+
+func TestCheckUnusedWithFilenames(t *testing.T) {
+	spec := filepath.Join(t.TempDir(), "spec.synta")
+	assert.Nil(t, os.WriteFile(spec, []byte("number = [0-9]{2}\nunused = [a-z]+\next = pdf\n> number.ext\n"), 0644))
+
+	var stdout bytes.Buffer
+	p := &checkCommand{stdout: &stdout}
+	f := flag.NewFlagSet("check", flag.ContinueOnError)
+	p.SetFlags(f)
+	assert.Nil(t, f.Parse([]string{spec, "01.pdf", "1.pdf"}))
+	assert.Equal(t, subcommands.ExitFailure, p.Execute(context.Background(), f))
+	assert.Equal(t, "Your Synta file contains unused definitions: unused\n01.pdf: ok\n1.pdf: does not match\n", stdout.String())
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/cartabinaria/synta"
+	"github.com/google/subcommands"
+)
+
+type diffCommand struct {
+	json bool
+	// stdout receives the differences, os.Stdout when nil
+	stdout io.Writer
+}
+
+func (*diffCommand) Name() string { return "diff" }
+func (*diffCommand) Synopsis() string {
+	return "Print the semantic differences between two synta files."
+}
+func (*diffCommand) Usage() string {
+	return `diff [-json] <old> <new>:
+  Print the semantic differences between two synta files.
+  Exits with a failure status if the files differ.
+`
+}
+
+func (p *diffCommand) SetFlags(f *flag.FlagSet) {
+	f.BoolVar(&p.json, "json", false, "Print the differences as json")
+}
+
+func (p *diffCommand) Execute(_ context.Context, f *flag.FlagSet, _ ...interface{}) subcommands.ExitStatus {
+	if f.NArg() != 2 {
+		fmt.Println(p.Usage())
+		return subcommands.ExitUsageError
+	}
+
+	stdout := p.stdout
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+	parse := func(path string) (*synta.Synta, subcommands.ExitStatus) {
+		if p.json {
+			return parsePathJSON(path, stdout)
+		}
+		return parsePath(path)
+	}
+
+	oldSyntaPtr, status := parse(f.Arg(0))
+	if status != subcommands.ExitSuccess {
+		return status
+	}
+	newSyntaPtr, status := parse(f.Arg(1))
+	if status != subcommands.ExitSuccess {
+		return status
+	}
+
+	changes := synta.Diff(*oldSyntaPtr, *newSyntaPtr)
+	if p.json {
+		if changes == nil {
+			changes = []synta.Change{}
+		}
+		res, err := json.Marshal(changes)
+		if err != nil {
+			fmt.Fprintf(stdout, "Error while converting the differences to json: %v\n", err)
+			return subcommands.ExitFailure
+		}
+		fmt.Fprintln(stdout, string(res))
+	} else {
+		for _, change := range changes {
+			fmt.Fprintln(stdout, change)
+		}
+	}
+
+	if len(changes) > 0 {
+		return subcommands.ExitFailure
+	}
+	return subcommands.ExitSuccess
+}
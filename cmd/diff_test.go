@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/subcommands"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	dir := t.TempDir()
+	old := filepath.Join(dir, "old.synta")
+	assert.Nil(t, os.WriteFile(old, []byte("number = [0-9]{2}\next = pdf\n> number.ext\n"), 0644))
+	updated := filepath.Join(dir, "new.synta")
+	assert.Nil(t, os.WriteFile(updated, []byte("number = [0-9]{3}\ntitle = [a-z]+\next = pdf\n> number(-title)?.ext\n"), 0644))
+
+	diff := func(args ...string) (subcommands.ExitStatus, string) {
+		var stdout bytes.Buffer
+		p := &diffCommand{stdout: &stdout}
+		f := flag.NewFlagSet("diff", flag.ContinueOnError)
+		p.SetFlags(f)
+		assert.Nil(t, f.Parse(args))
+		return p.Execute(context.Background(), f), stdout.String()
+	}
+
+	status, output := diff(old, old)
+	assert.Equal(t, subcommands.ExitSuccess, status)
+	assert.Equal(t, "", output)
+
+	status, output = diff(old, updated)
+	assert.Equal(t, subcommands.ExitFailure, status)
+	assert.Contains(t, output, "~ `number`: [0-9]{2} -> [0-9]{3}\n")
+	assert.Contains(t, output, "+ `title`: [a-z]+\n")
+
+	status, output = diff("-json", old, updated)
+	assert.Equal(t, subcommands.ExitFailure, status)
+	var changes []map[string]any
+	assert.Nil(t, json.Unmarshal([]byte(output), &changes))
+	assert.Len(t, changes, 3)
+
+	status, output = diff("-json", old, old)
+	assert.Equal(t, subcommands.ExitSuccess, status)
+	assert.Equal(t, "[]\n", output)
+}
+
+func TestDiffJSONWithBrokenSpec(t *testing.T) {
+	dir := t.TempDir()
+	spec := filepath.Join(dir, "spec.synta")
+	assert.Nil(t, os.WriteFile(spec, []byte("number = [0-9]{2}\next = pdf\n> number.ext\n"), 0644))
+	broken := filepath.Join(dir, "broken.synta")
+	assert.Nil(t, os.WriteFile(broken, []byte("number = [0-9]{2}\next = pdf\n> number_x.ext\n"), 0644))
+
+	for _, args := range [][]string{{spec, broken}, {broken, spec}} {
+		var stdout bytes.Buffer
+		p := &diffCommand{stdout: &stdout}
+		f := flag.NewFlagSet("diff", flag.ContinueOnError)
+		p.SetFlags(f)
+		assert.Nil(t, f.Parse(append([]string{"-json"}, args...)))
+		assert.Equal(t, subcommands.ExitFailure, p.Execute(context.Background(), f))
+
+		var output map[string]any
+		assert.Nil(t, json.Unmarshal(stdout.Bytes(), &output))
+		assert.Equal(t, float64(3), output["line"])
+		assert.Equal(t, float64(8), output["pos"])
+		assert.Contains(t, output["message"], "Invalid char at column 7")
+	}
+}
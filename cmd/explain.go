@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/cartabinaria/synta"
+	"github.com/google/subcommands"
+)
+
+type explainCommand struct {
+	// stdout receives the explanation, os.Stdout when nil
+	stdout io.Writer
+}
+
+func (*explainCommand) Name() string     { return "explain" }
+func (*explainCommand) Synopsis() string { return "Describe a synta file in plain English." }
+func (*explainCommand) Usage() string {
+	return `explain <file> [filename ...]:
+  Describe the filenames accepted by a synta file in plain English, followed
+  by each identifier, whether it is required, its comments and its regexp,
+  and by the rules constraining their values. Each given filename is then
+  checked, explaining why it is rejected, such as a broken rule.
+  Exits with a failure status if any filename is rejected.
+`
+}
+
+func (p *explainCommand) SetFlags(f *flag.FlagSet) {}
+
+func (p *explainCommand) Execute(_ context.Context, f *flag.FlagSet, _ ...interface{}) subcommands.ExitStatus {
+	syntaFilePtr, status := parseFile(p, f)
+	if status != subcommands.ExitSuccess {
+		return status
+	}
+
+	stdout := p.stdout
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+	fmt.Fprint(stdout, explain(*syntaFilePtr))
+
+	status = subcommands.ExitSuccess
+	if f.NArg() > 1 {
+		fmt.Fprintln(stdout)
+	}
+	for _, filename := range f.Args()[1:] {
+		if _, err := syntaFilePtr.Extract(filename); err != nil {
+			fmt.Fprintln(stdout, err)
+			status = subcommands.ExitFailure
+		} else {
+			fmt.Fprintf(stdout, "%s: ok\n", filename)
+		}
+	}
+	return status
+}
+
+// explain describes the filename of the spec in a sentence, then each of its
+// identifiers in order of appearance
+func explain(s synta.Synta) (explanation string) {
+	extensions := s.Filename.Extensions
+	if len(extensions) == 0 && s.Filename.HasExtension() {
+		extensions = []synta.Identifier{s.Filename.Extension}
+	}
+	ext := []string{}
+	for _, id := range extensions {
+		ext = append(ext, "<"+string(id)+">")
+	}
+
+	sentence := strings.Join(explainSegments(s.Filename.Segments, false), ", then ")
+	ending := ", ending in ." + strings.Join(ext, " or ")
+	if len(ext) == 0 {
+		ending = ", without extension"
+	}
+	explanation = strings.ToUpper(sentence[:1]) + sentence[1:] + ending + ".\n\n"
+
+	required, _, _ := s.ClassifyDefinitions()
+	seen := map[synta.Identifier]bool{}
+	for _, id := range append(identifiers(s.Filename.Segments), extensions...) {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		def := s.Definitions[id]
+		comments := def.Comments
+		if slices.Contains(extensions, id) && len(s.Filename.ExtensionComments) > 0 {
+			comments = s.Filename.ExtensionComments
+		}
+		kind := "optional"
+		if slices.Contains(required, id) {
+			kind = "required"
+		}
+
+		explanation += fmt.Sprintf("<%s>, %s", id, kind)
+		if len(comments) > 0 {
+			explanation += ": " + strings.Join(comments, " ")
+		}
+		explanation += "\n  regexp: " + def.Source() + "\n"
+	}
+
+	rules := []string{}
+	for _, c := range s.Constraints {
+		switch c.Kind {
+		case synta.ConstraintRequiredUnless:
+			rules = append(rules, fmt.Sprintf("<%s> is required unless <%s> is present", c.Subject, c.Other))
+		case synta.ConstraintEqual:
+			rules = append(rules, fmt.Sprintf("every <%s> must have the same value", c.Subject))
+		}
+	}
+	if len(rules) > 0 {
+		explanation += "\nRules:\n  " + strings.Join(rules, "\n  ") + "\n"
+	}
+	return
+}
+
+// explainSegments describes each segment with a short phrase, telling apart
+// the identifiers of optional segments
+func explainSegments(segments []synta.Segment, optional bool) (phrases []string) {
+	for _, segment := range segments {
+		switch segment.Kind {
+		case synta.SegmentTypeIdentifier:
+			if optional {
+				phrases = append(phrases, "an optional <"+string(*segment.Value)+">")
+			} else {
+				phrases = append(phrases, "a required <"+string(*segment.Value)+"> part")
+			}
+		case synta.SegmentTypeBackreference:
+			phrases = append(phrases, "the same <"+string(*segment.Value)+"> again")
+		case synta.SegmentTypeInline:
+			phrases = append(phrases, "the text matching `"+segment.Inline.Source()+"`")
+		case synta.SegmentTypeLiteral:
+			phrases = append(phrases, "the text `"+string(*segment.Value)+"`")
+		case synta.SegmentTypeOptional:
+			phrases = append(phrases, strings.Join(explainSegments(segment.Subsegments, true), ", then "))
+		case synta.SegmentTypeAlternation:
+			branches := []string{}
+			for _, branch := range segment.Subsegments {
+				if branch.Kind == synta.SegmentTypeInline {
+					branches = append(branches, "the text matching `"+branch.Inline.Source()+"`")
+				} else {
+					branches = append(branches, "<"+string(*branch.Value)+">")
+				}
+			}
+			prefix := "either "
+			if optional {
+				prefix = "optionally either "
+			}
+			phrases = append(phrases, prefix+strings.Join(branches, " or "))
+		case synta.SegmentTypeRepeat:
+			phrases = append(phrases, "one or more times "+strings.Join(explainSegments(segment.Subsegments, optional), ", then "))
+		}
+	}
+	return
+}
+
+// identifiers returns the identifiers of the segments in order of appearance
+func identifiers(segments []synta.Segment) (ids []synta.Identifier) {
+	for _, segment := range segments {
+		switch segment.Kind {
+		case synta.SegmentTypeIdentifier:
+			ids = append(ids, *segment.Value)
+		case synta.SegmentTypeOptional, synta.SegmentTypeAlternation, synta.SegmentTypeRepeat:
+			ids = append(ids, identifiers(segment.Subsegments)...)
+		}
+	}
+	return
+}
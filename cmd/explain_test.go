@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/subcommands"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplain(t *testing.T) {
+	spec := filepath.Join(t.TempDir(), "spec.synta")
+	err := os.WriteFile(spec, []byte(`; the lesson number
+lesson = [0-9]{2}
+; a short title
+subtitle = [a-z]+
+ext = pdf
+> lesson(-subtitle)?.ext ; the format
+`), 0644)
+	assert.Nil(t, err)
+
+	var stdout bytes.Buffer
+	p := &explainCommand{stdout: &stdout}
+	f := flag.NewFlagSet("explain", flag.ContinueOnError)
+	p.SetFlags(f)
+	assert.Nil(t, f.Parse([]string{spec}))
+	assert.Equal(t, subcommands.ExitSuccess, p.Execute(context.Background(), f))
+	assert.Equal(t, `A required <lesson> part, then an optional <subtitle>, ending in .<ext>.
+
+<lesson>, required: the lesson number
+  regexp: [0-9]{2}
+<subtitle>, optional: a short title
+  regexp: [a-z]+
+<ext>, required: the format
+  regexp: pdf
+`, stdout.String())
+}
+
+func TestExplainConstraints(t *testing.T) {
+	spec := filepath.Join(t.TempDir(), "spec.synta")
+	err := os.WriteFile(spec, []byte(`name = [a-z]+
+tag = [a-z]+
+author = [a-z]+
+ext = pdf
+! require tag unless author
+> name(-tag)?(-author)?.ext
+`), 0644)
+	assert.Nil(t, err)
+
+	var stdout bytes.Buffer
+	p := &explainCommand{stdout: &stdout}
+	f := flag.NewFlagSet("explain", flag.ContinueOnError)
+	p.SetFlags(f)
+	assert.Nil(t, f.Parse([]string{spec, "notes-draft.pdf", "notes.pdf"}))
+	assert.Equal(t, subcommands.ExitFailure, p.Execute(context.Background(), f))
+	assert.Contains(t, stdout.String(), `
+Rules:
+  <tag> is required unless <author> is present
+
+notes-draft.pdf: ok
+filename `+"`notes.pdf` does not match the spec: `tag` is required unless `author` is present\n")
+}
+
+func TestExplainBackreferences(t *testing.T) {
+	spec := filepath.Join(t.TempDir(), "spec.synta")
+	err := os.WriteFile(spec, []byte("code = [0-9]{3}\nname = [a-z]+\next = pdf\n> code-name-=code.ext\n"), 0644)
+	assert.Nil(t, err)
+
+	var stdout bytes.Buffer
+	p := &explainCommand{stdout: &stdout}
+	f := flag.NewFlagSet("explain", flag.ContinueOnError)
+	p.SetFlags(f)
+	assert.Nil(t, f.Parse([]string{spec, "123-notes-123.pdf", "123-notes-456.pdf"}))
+	assert.Equal(t, subcommands.ExitFailure, p.Execute(context.Background(), f))
+	assert.Contains(t, stdout.String(), `
+Rules:
+  every <code> must have the same value
+
+123-notes-123.pdf: ok
+filename `+"`123-notes-456.pdf` does not match the spec: every `code` must have the same value, found `123` and `456`\n")
+}
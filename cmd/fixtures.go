@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/subcommands"
+)
+
+type fixturesCommand struct {
+	n       int
+	valid   int
+	invalid int
+	seed    int
+	out     string
+}
+
+func (*fixturesCommand) Name() string     { return "fixtures" }
+func (*fixturesCommand) Synopsis() string { return "Generate example filenames from a synta file." }
+func (*fixturesCommand) Usage() string {
+	return `fixtures [-n <count>] [-valid <count>] [-invalid <count>] [-seed <seed>] [-out <dir>] <file>:
+  Generate filenames matching the synta file, prefixed by "+", and near
+  misses which do not match it, prefixed by "-". With -out, an empty file is
+  created for each filename in the valid and invalid subdirectories instead.
+`
+}
+
+func (p *fixturesCommand) SetFlags(f *flag.FlagSet) {
+	f.IntVar(&p.n, "n", 10, "Number of valid and invalid filenames to generate")
+	f.IntVar(&p.valid, "valid", -1, "Number of valid filenames to generate, overrides -n")
+	f.IntVar(&p.invalid, "invalid", -1, "Number of invalid filenames to generate, overrides -n")
+	f.IntVar(&p.seed, "seed", 0, "Seed selecting the generated filenames")
+	f.StringVar(&p.out, "out", "", "Directory where the files are created")
+}
+
+func (p *fixturesCommand) Execute(_ context.Context, f *flag.FlagSet, _ ...interface{}) subcommands.ExitStatus {
+	syntaFilePtr, status := parseFile(p, f)
+	if status != subcommands.ExitSuccess {
+		return status
+	}
+
+	validCount, invalidCount := p.n, p.n
+	if p.valid >= 0 {
+		validCount = p.valid
+	}
+	if p.invalid >= 0 {
+		invalidCount = p.invalid
+	}
+
+	valid, invalid, err := syntaFilePtr.CorpusWithSeed(max(validCount, invalidCount), p.seed)
+	if err != nil {
+		fmt.Printf("Error while generating the filenames: %v\n", err)
+		return subcommands.ExitFailure
+	}
+	valid, invalid = valid[:min(validCount, len(valid))], invalid[:min(invalidCount, len(invalid))]
+	if len(valid) < validCount || len(invalid) < invalidCount {
+		fmt.Printf("Could only generate %d valid and %d invalid filenames\n", len(valid), len(invalid))
+		return subcommands.ExitFailure
+	}
+
+	if p.out == "" {
+		for _, filename := range valid {
+			fmt.Println("+ " + filename)
+		}
+		for _, filename := range invalid {
+			fmt.Println("- " + filename)
+		}
+		return subcommands.ExitSuccess
+	}
+
+	if err = writeFixtures(filepath.Join(p.out, "valid"), valid); err == nil {
+		err = writeFixtures(filepath.Join(p.out, "invalid"), invalid)
+	}
+	if err != nil {
+		fmt.Printf("Error while writing the fixtures: %v\n", err)
+		return subcommands.ExitFailure
+	}
+	return subcommands.ExitSuccess
+}
+
+// writeFixtures creates an empty file for each filename in the directory
+func writeFixtures(dir string, filenames []string) (err error) {
+	if err = os.MkdirAll(dir, 0755); err != nil {
+		return
+	}
+	for _, filename := range filenames {
+		if strings.ContainsAny(filename, "/\x00") || filename == "." || filename == ".." {
+			return errors.New("cannot create a file named " + filename)
+		}
+		if err = os.WriteFile(filepath.Join(dir, filename), nil, 0644); err != nil {
+			return
+		}
+	}
+	return
+}
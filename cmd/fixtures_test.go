@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/subcommands"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFixtures(t *testing.T) {
+	dir := t.TempDir()
+	spec := filepath.Join(dir, "spec.synta")
+	err := os.WriteFile(spec, []byte(`name = [a-z]+
+year = [0-9]{4}
+ext = pdf|txt
+> name-year.ext
+`), 0644)
+	assert.Nil(t, err)
+
+	p := &fixturesCommand{}
+	f := flag.NewFlagSet("fixtures", flag.ContinueOnError)
+	p.SetFlags(f)
+	out := filepath.Join(dir, "out")
+	assert.Nil(t, f.Parse([]string{"-valid", "5", "-invalid", "3", "-out", out, spec}))
+	assert.Equal(t, subcommands.ExitSuccess, p.Execute(context.Background(), f))
+
+	s, status := parsePath(spec)
+	assert.Equal(t, subcommands.ExitSuccess, status)
+
+	valid, err := os.ReadDir(filepath.Join(out, "valid"))
+	assert.Nil(t, err)
+	assert.Len(t, valid, 5)
+	for _, entry := range valid {
+		_, err := s.Extract(entry.Name())
+		assert.Nil(t, err, entry.Name())
+	}
+
+	invalid, err := os.ReadDir(filepath.Join(out, "invalid"))
+	assert.Nil(t, err)
+	assert.Len(t, invalid, 3)
+	for _, entry := range invalid {
+		_, err := s.Extract(entry.Name())
+		assert.NotNil(t, err, entry.Name())
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/google/subcommands"
+)
+
+type generateCommand struct {
+	n int
+	// stdout receives the filenames, os.Stdout when nil
+	stdout io.Writer
+}
+
+func (*generateCommand) Name() string     { return "generate" }
+func (*generateCommand) Synopsis() string { return "Print example filenames matching a synta file." }
+func (*generateCommand) Usage() string {
+	return `generate [-n <count>] <file>:
+  Print distinct example filenames matching the synta file, one per line, to
+  sanity-check its grammar.
+`
+}
+
+func (p *generateCommand) SetFlags(f *flag.FlagSet) {
+	f.IntVar(&p.n, "n", 1, "Number of filenames to generate")
+}
+
+func (p *generateCommand) Execute(_ context.Context, f *flag.FlagSet, _ ...interface{}) subcommands.ExitStatus {
+	syntaFilePtr, status := parseFile(p, f)
+	if status != subcommands.ExitSuccess {
+		return status
+	}
+
+	filenames, _, err := syntaFilePtr.Corpus(p.n)
+	if err != nil {
+		fmt.Printf("Error while generating the filenames: %v\n", err)
+		return subcommands.ExitFailure
+	}
+	if len(filenames) < p.n {
+		fmt.Printf("Could only generate %d filenames\n", len(filenames))
+		return subcommands.ExitFailure
+	}
+
+	stdout := p.stdout
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+	for _, filename := range filenames {
+		if matches, err := syntaFilePtr.Match(filename); err != nil || !matches {
+			fmt.Printf("Generated %s, which does not match the synta file\n", filename)
+			return subcommands.ExitFailure
+		}
+		fmt.Fprintln(stdout, filename)
+	}
+	return subcommands.ExitSuccess
+}
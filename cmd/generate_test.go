@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/subcommands"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	spec := filepath.Join(t.TempDir(), "spec.synta")
+	err := os.WriteFile(spec, []byte(`name = [a-z]+
+year = [0-9]{4}
+tag = [a-z]+
+ext = pdf|txt
+> name-year(-tag)?.ext
+`), 0644)
+	assert.Nil(t, err)
+
+	var stdout bytes.Buffer
+	p := &generateCommand{stdout: &stdout}
+	f := flag.NewFlagSet("generate", flag.ContinueOnError)
+	p.SetFlags(f)
+	assert.Nil(t, f.Parse([]string{"-n", "3", spec}))
+	assert.Equal(t, subcommands.ExitSuccess, p.Execute(context.Background(), f))
+
+	s, status := parsePath(spec)
+	assert.Equal(t, subcommands.ExitSuccess, status)
+	lines := strings.Split(strings.TrimSuffix(stdout.String(), "\n"), "\n")
+	assert.Len(t, lines, 3)
+	seen := map[string]bool{}
+	for _, line := range lines {
+		assert.False(t, seen[line], line)
+		seen[line] = true
+		matches, err := s.Match(line)
+		assert.Nil(t, err)
+		assert.True(t, matches, line)
+	}
+}
@@ -8,7 +8,6 @@ import (
 
 	"github.com/cartabinaria/synta"
 	"github.com/google/subcommands"
-	"github.com/invopop/jsonschema"
 )
 
 type jsonSchemaCommand struct{}
@@ -24,7 +23,7 @@ func (*jsonSchemaCommand) Usage() string {
 func (p *jsonSchemaCommand) SetFlags(f *flag.FlagSet) {}
 
 func (p *jsonSchemaCommand) Execute(_ context.Context, f *flag.FlagSet, _ ...interface{}) subcommands.ExitStatus {
-	res, err := json.Marshal(jsonschema.Reflect(&synta.Synta{}))
+	res, err := json.Marshal(synta.JSONSchema())
 	if err != nil {
 		fmt.Printf("Error from converting Synta to json schema\n")
 		return subcommands.ExitFailure
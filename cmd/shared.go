@@ -1,8 +1,11 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"io/ioutil"
 
 	"github.com/cartabinaria/synta"
@@ -16,6 +19,10 @@ func parseFile(p subcommands.Command, f *flag.FlagSet) (*synta.Synta, subcommand
 		return nil, subcommands.ExitUsageError
 	}
 
+	return parsePath(filename)
+}
+
+func parsePath(filename string) (*synta.Synta, subcommands.ExitStatus) {
 	contents, err := ioutil.ReadFile(filename)
 	if err != nil {
 		fmt.Printf("Error while reading file: %s\n%v\n", filename, err)
@@ -29,3 +36,42 @@ func parseFile(p subcommands.Command, f *flag.FlagSet) (*synta.Synta, subcommand
 	}
 	return &synta, subcommands.ExitSuccess
 }
+
+// diagnostic is a parse error as printed with -json, for editors to locate it
+type diagnostic struct {
+	Line    int    `json:"line"`
+	Pos     int    `json:"pos"`
+	Message string `json:"message"`
+}
+
+// parsePathJSON works like parsePath, but prints a failure to stdout as a
+// JSON diagnostic. Errors without a position, such as a missing file, are
+// reported at line 0.
+func parsePathJSON(filename string, stdout io.Writer) (*synta.Synta, subcommands.ExitStatus) {
+	contents, err := ioutil.ReadFile(filename)
+	if err == nil {
+		s, e := synta.ParseSynta(string(contents))
+		if e == nil {
+			return &s, subcommands.ExitSuccess
+		}
+		err = e
+	}
+
+	d := diagnostic{Message: err.Error()}
+	var se synta.SyntaError
+	if errors.As(err, &se) {
+		d.Line, d.Pos = se.Line, se.Pos
+	}
+	printJSON(stdout, d)
+	return nil, subcommands.ExitFailure
+}
+
+// printJSON prints the value to stdout as compact JSON on a single line
+func printJSON(stdout io.Writer, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		fmt.Fprintf(stdout, "Error while encoding the JSON: %v\n", err)
+		return
+	}
+	fmt.Fprintln(stdout, string(data))
+}
@@ -18,6 +18,10 @@ func main() {
 	subcommands.Register(&jsonSchemaCommand{}, "")
 	subcommands.Register(&regexpCommand{}, "")
 	subcommands.Register(&jsonCommand{}, "")
+	subcommands.Register(&diffCommand{}, "")
+	subcommands.Register(&fixturesCommand{}, "")
+	subcommands.Register(&explainCommand{}, "")
+	subcommands.Register(&generateCommand{}, "")
 
 	flag.Parse()
 	ctx := context.Background()
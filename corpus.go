@@ -0,0 +1,74 @@
+package synta
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// maxCorpusAttempts bounds the number of filenames generated for each one
+// requested, as specs accepting few filenames yield many duplicates
+const maxCorpusAttempts = 16
+
+// Corpus generates up to n distinct filenames accepted by the spec, along with
+// up to n near misses obtained by replacing the value of a single segment of a
+// valid filename with one its definition rejects. Every near miss is rejected
+// by the spec. Fewer filenames are returned when the spec does not accept
+// enough distinct ones.
+func (s Synta) Corpus(n int) (valid, invalid []string, err error) {
+	return s.CorpusWithSeed(n, 0)
+}
+
+// CorpusWithSeed works like Corpus, but the seed selects a different, yet
+// deterministic, set of filenames
+func (s Synta) CorpusWithSeed(n int, seed int) (valid, invalid []string, err error) {
+	expr, names, err := s.buildRegexp()
+	if err != nil {
+		return
+	}
+
+	for v := seed; len(valid) < n && v < seed+n*maxCorpusAttempts; v++ {
+		filename, e := generator{variation: v}.generateFilename(s)
+		if e != nil || slices.Contains(valid, filename) {
+			continue
+		}
+		if _, e = s.Extract(filename); e == nil {
+			valid = append(valid, filename)
+		}
+	}
+
+	for i := 0; len(invalid) < n && i < len(valid)*maxCorpusAttempts; i++ {
+		filename, ok := s.mutate(expr, names, valid[i%len(valid)], i, i/len(valid))
+		if ok && !slices.Contains(invalid, filename) {
+			invalid = append(invalid, filename)
+		}
+	}
+	return
+}
+
+// mutate replaces the value of the given segment of a valid filename with one
+// of a few replacements, and tells whether the result is rejected by the spec
+func (s Synta) mutate(expr *regexp.Regexp, names map[string]Identifier, filename string, segment, replacement int) (mutated string, ok bool) {
+	match := expr.FindStringSubmatchIndex(filename)
+	spans := [][2]int{}
+	for i, name := range expr.SubexpNames() {
+		if _, isGroup := names[name]; isGroup && match[2*i] >= 0 {
+			spans = append(spans, [2]int{match[2*i], match[2*i+1]})
+		}
+	}
+	if len(spans) == 0 {
+		return
+	}
+
+	span := spans[segment%len(spans)]
+	value := filename[span[0]:span[1]]
+	replacements := []string{strings.ToUpper(value), value + "~", value[:len(value)/2], "~"}
+	if replacement >= len(replacements) {
+		return
+	}
+
+	mutated = filename[:span[0]] + replacements[replacement] + filename[span[1]:]
+	_, e := s.Extract(mutated)
+	ok = e != nil
+	return
+}
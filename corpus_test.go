@@ -0,0 +1,56 @@
+package synta
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCorpus(t *testing.T) {
+	synta, err := ParseSynta(extractInput)
+	assert.Nil(t, err)
+
+	valid, invalid, err := synta.Corpus(10)
+	assert.Nil(t, err)
+	assert.Len(t, valid, 10)
+	assert.Len(t, invalid, 10)
+
+	for _, filename := range valid {
+		_, err := synta.Extract(filename)
+		assert.Nil(t, err, filename)
+	}
+	for _, filename := range invalid {
+		_, err := synta.Extract(filename)
+		assert.NotNil(t, err, filename)
+	}
+}
+
+func TestCorpusWithFewFilenames(t *testing.T) {
+	synta := MustSynta(`name = a|b
+ext = pdf
+> name.ext`)
+
+	valid, invalid, err := synta.Corpus(5)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"a.pdf", "b.pdf"}, valid)
+	assert.NotEmpty(t, invalid)
+	for _, filename := range invalid {
+		_, err := synta.Extract(filename)
+		assert.NotNil(t, err, filename)
+	}
+}
+
+func TestCorpusWithSeed(t *testing.T) {
+	synta, err := ParseSynta(extractInput)
+	assert.Nil(t, err)
+
+	first, _, err := synta.CorpusWithSeed(3, 7)
+	assert.Nil(t, err)
+	again, _, err := synta.CorpusWithSeed(3, 7)
+	assert.Nil(t, err)
+	assert.Equal(t, first, again)
+
+	other, _, err := synta.Corpus(3)
+	assert.Nil(t, err)
+	assert.NotEqual(t, first, other)
+}
@@ -1,13 +1,19 @@
 package synta
 
 import (
+	"fmt"
 	"regexp"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
 )
 
 // A regexp which describes an identifier
-var IdentifierRegexp = regexp.MustCompile("[a-z]+")
+var IdentifierRegexp = regexp.MustCompile(`^\p{L}+$`)
 
-// An Identifier is a lowercase alphabetical string.
+// An Identifier is a string of Unicode letters of any case.
 // It corresponds to the <id> BNF definition
 type Identifier string
 
@@ -17,6 +23,103 @@ type Identifier string
 type Definition struct {
 	Comments []string
 	Regexp   *regexp.Regexp
+	// Pattern is the source of the regexp. When the spec is parsed with
+	// Options.LazyCompile, Regexp is nil until Compiled is called.
+	Pattern string
+	// MinLen and MaxLen bound the length in characters of the values, in
+	// addition to the regexp. They are written `len(min,max)` after the
+	// regexp, and zero means no bound.
+	MinLen int
+	MaxLen int
+	// lazy holds the regexp compiled by Compiled, shared by the copies of a
+	// definition parsed with Options.LazyCompile
+	lazy *lazyRegexp
+}
+
+// lazyRegexp is a regexp compiled at most once, on first use
+type lazyRegexp struct {
+	once sync.Once
+	expr *regexp.Regexp
+	err  error
+}
+
+// Source returns the source of the definition's regexp, whether it has been
+// compiled or not
+func (d Definition) Source() string {
+	if d.Regexp != nil {
+		return d.Regexp.String()
+	}
+	return d.Pattern
+}
+
+// Expression returns the right hand side of the definition as written in a
+// spec: the source of the regexp followed by its length annotation, if any
+func (d Definition) Expression() string {
+	if d.MinLen == 0 && d.MaxLen == 0 {
+		return d.Source()
+	}
+
+	bound := func(n int) string {
+		if n == 0 {
+			return ""
+		}
+		return strconv.Itoa(n)
+	}
+	return d.Source() + " len(" + bound(d.MinLen) + "," + bound(d.MaxLen) + ")"
+}
+
+// HasAnchors tells whether the regexp of the definition contains anchors,
+// such as ^ or $. Since definitions are embedded in the regexp of the whole
+// filename, anchors make it match nothing or behave unexpectedly. Invalid
+// regexps have no anchors.
+func (d Definition) HasAnchors() bool {
+	re, err := syntax.Parse(d.Source(), syntax.Perl)
+	return err == nil && hasAnchors(re)
+}
+
+func hasAnchors(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText:
+		return true
+	}
+	for _, sub := range re.Sub {
+		if hasAnchors(sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkLength tells whether the value respects the length bounds of the
+// definition
+func (d Definition) checkLength(value string) (err error) {
+	length := utf8.RuneCountInString(value)
+	if d.MinLen > 0 && length < d.MinLen {
+		err = fmt.Errorf("`%s` is %d characters long, expected at least %d", value, length, d.MinLen)
+	} else if d.MaxLen > 0 && length > d.MaxLen {
+		err = fmt.Errorf("`%s` is %d characters long, expected at most %d", value, length, d.MaxLen)
+	}
+	return
+}
+
+// Compiled returns the compiled regexp of the definition, compiling its
+// Pattern when it was parsed lazily. Definitions parsed with
+// Options.LazyCompile are compiled once, and the copies read from
+// Synta.Definitions share the result; other definitions without a Regexp are
+// compiled on each call.
+func (d Definition) Compiled() (expr *regexp.Regexp, err error) {
+	switch {
+	case d.Regexp != nil:
+		expr = d.Regexp
+	case d.lazy != nil:
+		d.lazy.once.Do(func() {
+			d.lazy.expr, d.lazy.err = regexp.Compile(d.Pattern)
+		})
+		expr, err = d.lazy.expr, d.lazy.err
+	default:
+		expr, err = regexp.Compile(d.Pattern)
+	}
+	return
 }
 
 type SegmentType uint
@@ -24,27 +127,280 @@ type SegmentType uint
 const (
 	SegmentTypeIdentifier = iota
 	SegmentTypeOptional
+	// SegmentTypeBackreference is written `=id` and must have the same value
+	// as the other segments of the same identifier
+	SegmentTypeBackreference
+	// SegmentTypeInline is written `{pattern}` and matches its own pattern
+	// instead of the definition of an identifier, so its value is not
+	// extracted
+	SegmentTypeInline
+	// SegmentTypeAlternation is written `(a|b)` and matches exactly one of
+	// its Subsegments, the branches, which are identifier or inline segments.
+	// Inside an optional segment, `(-a|-b)?` is short for `(-(a|b))?`.
+	SegmentTypeAlternation
+	// SegmentTypeRepeat is written `(-a)+` and matches its Subsegments one or
+	// more times, each time preceded by a separator
+	SegmentTypeRepeat
+	// SegmentTypeLiteral is written with escaped separators, such as `\-` or
+	// `\.`, and matches the text held by its Value. It is glued to the
+	// segments around it, without any separator, and may only appear outside
+	// of optional segments.
+	SegmentTypeLiteral
 )
 
+var segmentTypeNames = []string{
+	SegmentTypeIdentifier:    "Identifier",
+	SegmentTypeOptional:      "Optional",
+	SegmentTypeBackreference: "Backreference",
+	SegmentTypeInline:        "Inline",
+	SegmentTypeAlternation:   "Alternation",
+	SegmentTypeRepeat:        "Repeat",
+	SegmentTypeLiteral:       "Literal",
+}
+
+func (t SegmentType) String() string {
+	if int(t) < len(segmentTypeNames) {
+		return segmentTypeNames[t]
+	}
+	return fmt.Sprintf("SegmentType(%d)", uint(t))
+}
+
 // A Segment is a section of the main filename
 // It corresponds to the <segment> BNF definition
+// Inline segments have no Value, their pattern is held by Inline instead
+// Alternations and repeats have no Value either, only Subsegments
 type Segment struct {
 	Kind        SegmentType
 	Value       *Identifier
 	Subsegments []Segment
+	Inline      *Definition
 }
 
 // Filename represents the flename defintion, made up
-// of a series of segments and a file extension
+// of a series of segments and a file extension, along
+// with the comments describing it. The comments describing
+// the extension trail the declaration, as in
+// `> name.ext ; the format of the file`.
+// A filename ending with an alternation of extensions, as in
+// `> photo.(jpg|png)`, lists all of them in Extensions, each referencing a
+// definition; Extension is then the first of them.
 type Filename struct {
-	Segments  []Segment
-	Extension Identifier
+	Segments          []Segment
+	Extension         Identifier
+	Extensions        []Identifier
+	Comments          []string
+	ExtensionComments []string
+}
+
+type NodeType uint
+
+const (
+	NodeTypeDefinition NodeType = iota
+	NodeTypeFilename
+)
+
+// A Node is a top level element of a Synta file: either a definition, along
+// with its identifier, or the filename. Only the pointer matching the Type is
+// set. Line is the 1-based line declaring the node, or zero when the node was
+// not parsed from a file. Typed converts it to a TypedNode, which suits type
+// switches.
+type Node struct {
+	Type       NodeType
+	Identifier Identifier
+	Definition *Definition
+	Filename   *Filename
+	Line       int
 }
 
 // Synta represents the contents of a Synta file
 // It corresponds to the <language> BNF definition
 // The last segment of the Filename is the extension
+// Nodes holds the elements of the file in declaration order
 type Synta struct {
 	Definitions map[Identifier]Definition
 	Filename    Filename
+	Nodes       []Node
+	Constraints []Constraint
+	// LowercaseInput lowercases filenames before matching them, see
+	// Options.LowercaseInput
+	LowercaseInput bool
+	// MatchStrategy decides how ambiguous filenames are extracted, see
+	// Options.MatchStrategy
+	MatchStrategy MatchStrategy
+	// Separator stands between the segments of the filenames, in place of the
+	// `-` written in the filename declaration, as declared by the
+	// `! separator = <sep>` directive. It is `-` when empty.
+	Separator string
+	// Alternatives are the filenames declared after the first one, when
+	// parsing with Options.MultipleFilenames. Match, MatchAll, MatchFold and
+	// Matcher accept a filename conforming to any of them, each checked
+	// against the constraints on the identifiers it uses. Directives,
+	// builtins, the classification of the definitions, Extensions,
+	// RequiresFeatures, Validate, Diff, Merge, Clear, Equal, String and
+	// format.Format account for them too.
+	//
+	// The other APIs ignore them and only read Filename: BuildRegexp,
+	// BuildRegexpWith, Regexp, GroupNames, GrokPattern, OpenAPISchema,
+	// Extract, ExtractAll, ExtractInto, ExtractWithDefaults, Captures,
+	// MatchPositions, MatchWithExtensions, Highlight, Distance, SameEntity,
+	// ImpactOf, Render, MigrateFilename, Example, Generate, Corpus,
+	// CorpusWithSeed, IsSatisfiable, SelfCheck, VariantTable, Summary, SExpr,
+	// SuggestFix and DetectExtensionOverlaps. The json package cannot
+	// represent them, and its ToJson rejects a spec having any.
+	Alternatives []Filename
+
+	key Identifier
+}
+
+// separator returns the separator standing between the segments of the
+// filenames
+func (s Synta) separator() string {
+	if s.Separator == "" {
+		return "-"
+	}
+	return s.Separator
+}
+
+// filenames returns the Filename of the spec followed by its Alternatives
+func (s Synta) filenames() []Filename {
+	return append([]Filename{s.Filename}, s.Alternatives...)
+}
+
+// String returns the filename declaration as it would be written in a Synta
+// file, without the leading "> "
+func (f Filename) String() string {
+	if !f.HasExtension() {
+		return formatSegments(f.Segments) + "$"
+	}
+	return formatSegments(f.Segments) + "." + f.formatExtension()
+}
+
+// HasExtension tells whether the filename ends with an extension. A filename
+// declared with a trailing `$`, such as `> name$`, has none, and its
+// Extension is empty.
+func (f Filename) HasExtension() bool {
+	return f.Extension != "" || len(f.Extensions) > 0
+}
+
+// extensions returns the identifiers the extension can be, either the only
+// extension or the ones of the alternation, or nil when there is none
+func (f Filename) extensions() []Identifier {
+	if len(f.Extensions) > 0 {
+		return f.Extensions
+	}
+	if f.Extension == "" {
+		return nil
+	}
+	return []Identifier{f.Extension}
+}
+
+// identifiers returns the identifiers of the segments followed by the
+// extensions, in order of appearance
+func (f Filename) identifiers() []Identifier {
+	return append(getAllIdentifiers(f.Segments), f.extensions()...)
+}
+
+// formatExtension returns the extension as it is written in a Synta file,
+// such as `ext` or `(jpg|png)`
+func (f Filename) formatExtension() string {
+	if len(f.Extensions) == 0 {
+		return string(f.Extension)
+	}
+	exts := []string{}
+	for _, ext := range f.Extensions {
+		exts = append(exts, string(ext))
+	}
+	return "(" + strings.Join(exts, "|") + ")"
+}
+
+func formatSegments(segments []Segment) (expr string) {
+	for i, segment := range segments {
+		switch segment.Kind {
+		case SegmentTypeIdentifier:
+			expr += string(*segment.Value)
+		case SegmentTypeBackreference:
+			expr += "=" + string(*segment.Value)
+		case SegmentTypeInline:
+			expr += "{" + segment.Inline.Source() + "}"
+		case SegmentTypeOptional:
+			expr += "(-" + formatSegments(segment.Subsegments) + ")?"
+		case SegmentTypeAlternation:
+			expr += "(" + formatBranches(segment.Subsegments) + ")"
+		case SegmentTypeRepeat:
+			expr += "(-" + formatSegments(segment.Subsegments) + ")+"
+		case SegmentTypeLiteral:
+			for _, c := range *segment.Value {
+				expr += `\` + string(c)
+			}
+		}
+
+		if separated(segments, i) {
+			expr += "-"
+		}
+	}
+	return
+}
+
+// ownsSeparator tells whether the segment includes the separator preceding
+// it, as optional and repeated segments do
+func (seg Segment) ownsSeparator() bool {
+	return seg.Kind == SegmentTypeOptional || seg.Kind == SegmentTypeRepeat
+}
+
+// separated tells whether a separator follows the i-th segment. Segments
+// including their separator and literal segments, which are glued to the
+// ones around them, are not preceded by one.
+func separated(segments []Segment, i int) bool {
+	return i != len(segments)-1 && !segments[i+1].ownsSeparator() &&
+		segments[i].Kind != SegmentTypeLiteral && segments[i+1].Kind != SegmentTypeLiteral
+}
+
+// formatBranches formats the branches of an alternation, separated by "|"
+func formatBranches(branches []Segment) string {
+	formatted := []string{}
+	for _, branch := range branches {
+		formatted = append(formatted, formatSegments([]Segment{branch}))
+	}
+	return strings.Join(formatted, "|")
+}
+
+// SeparatorCount returns how many separators the fully expanded filename,
+// with every optional segment present, contains: the dashes between its
+// segments plus the dot before the extension, if any. Literal segments are
+// glued to the segments around them, and the text they hold is not counted.
+func (f Filename) SeparatorCount() (count int) {
+	count = countSeparators(f.Segments)
+	if f.HasExtension() {
+		count++
+	}
+	return
+}
+
+// MaxOptionalDepth returns the nesting level of the deepest optional segment,
+// or zero when the filename has no optional segments
+func (f Filename) MaxOptionalDepth() int {
+	return maxOptionalDepth(f.Segments)
+}
+
+func maxOptionalDepth(segments []Segment) (depth int) {
+	for _, seg := range segments {
+		if seg.Kind == SegmentTypeOptional {
+			depth = max(depth, 1+maxOptionalDepth(seg.Subsegments))
+		}
+	}
+	return
+}
+
+// countSeparators returns the number of separators between the segments,
+// including those inside optional and repeated segments, which are present
+func countSeparators(segments []Segment) (count int) {
+	for i, seg := range segments {
+		if seg.ownsSeparator() {
+			count += 1 + countSeparators(seg.Subsegments)
+		}
+		if separated(segments, i) {
+			count++
+		}
+	}
+	return
 }
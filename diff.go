@@ -0,0 +1,180 @@
+package synta
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ChangeKind tells how an element differs between two specs
+type ChangeKind uint
+
+const (
+	ChangeAdded ChangeKind = iota
+	ChangeRemoved
+	ChangeModified
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case ChangeAdded:
+		return "added"
+	case ChangeRemoved:
+		return "removed"
+	case ChangeModified:
+		return "modified"
+	}
+	return fmt.Sprintf("ChangeKind(%d)", uint(k))
+}
+
+func (k ChangeKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// A Change is a semantic difference between two specs. Changes to a
+// definition carry its identifier and expressions, followed by the comments
+// describing it when only those differ, as in `pdf ; the format`. Changes to
+// the filename have an empty identifier and carry the filename declarations,
+// of the alternatives too, or the directives which change, such as the
+// separator, the key and the `! require` constraints.
+type Change struct {
+	Kind       ChangeKind `json:"kind"`
+	Identifier Identifier `json:"identifier,omitempty"`
+	Old        string     `json:"old,omitempty"`
+	New        string     `json:"new,omitempty"`
+}
+
+func (c Change) String() string {
+	subject := "filename"
+	if c.Identifier != "" {
+		subject = "`" + string(c.Identifier) + "`"
+	}
+
+	switch c.Kind {
+	case ChangeAdded:
+		return fmt.Sprintf("+ %s: %s", subject, c.New)
+	case ChangeRemoved:
+		return fmt.Sprintf("- %s: %s", subject, c.Old)
+	default:
+		return fmt.Sprintf("~ %s: %s -> %s", subject, c.Old, c.New)
+	}
+}
+
+// Diff returns the semantic changes needed to go from the old spec to the new
+// one. Definitions are compared by identifier, on both their expression and
+// their comments, so reordering them does not produce any change. Changes
+// are sorted by identifier and the filename changes, if any, come last. The
+// alternatives are compared in declaration order, after the filename.
+func Diff(old, new Synta) (changes []Change) {
+	ids := []string{}
+	for id := range old.Definitions {
+		ids = append(ids, string(id))
+	}
+	for id := range new.Definitions {
+		if _, ok := old.Definitions[id]; !ok {
+			ids = append(ids, string(id))
+		}
+	}
+	sort.Strings(ids)
+
+	for _, raw := range ids {
+		id := Identifier(raw)
+		oldDef, inOld := old.Definitions[id]
+		newDef, inNew := new.Definitions[id]
+		switch {
+		case !inOld:
+			changes = append(changes, Change{ChangeAdded, id, "", newDef.Expression()})
+		case !inNew:
+			changes = append(changes, Change{ChangeRemoved, id, oldDef.Expression(), ""})
+		case oldDef.Expression() != newDef.Expression():
+			changes = append(changes, Change{ChangeModified, id, oldDef.Expression(), newDef.Expression()})
+		case !slices.Equal(oldDef.Comments, newDef.Comments):
+			changes = append(changes, Change{ChangeModified, id, describedExpression(oldDef), describedExpression(newDef)})
+		}
+	}
+
+	if oldFilename, newFilename := old.Filename.String(), new.Filename.String(); oldFilename != newFilename {
+		changes = append(changes, Change{ChangeModified, "", oldFilename, newFilename})
+	}
+	for i := 0; i < max(len(old.Alternatives), len(new.Alternatives)); i++ {
+		switch {
+		case i >= len(old.Alternatives):
+			changes = append(changes, Change{ChangeAdded, "", "", new.Alternatives[i].String()})
+		case i >= len(new.Alternatives):
+			changes = append(changes, Change{ChangeRemoved, "", old.Alternatives[i].String(), ""})
+		case old.Alternatives[i].String() != new.Alternatives[i].String():
+			changes = append(changes, Change{ChangeModified, "", old.Alternatives[i].String(), new.Alternatives[i].String()})
+		}
+	}
+	if old.separator() != new.separator() {
+		changes = append(changes, Change{ChangeModified, "", "! separator = " + old.separator(), "! separator = " + new.separator()})
+	}
+	switch oldKey, newKey := old.key, new.key; {
+	case oldKey == newKey:
+	case oldKey == "":
+		changes = append(changes, Change{ChangeAdded, "", "", "! key = " + string(newKey)})
+	case newKey == "":
+		changes = append(changes, Change{ChangeRemoved, "", "! key = " + string(oldKey), ""})
+	default:
+		changes = append(changes, Change{ChangeModified, "", "! key = " + string(oldKey), "! key = " + string(newKey)})
+	}
+	for _, c := range old.Constraints {
+		if c.IsDirective() && !slices.Contains(new.Constraints, c) {
+			changes = append(changes, Change{ChangeRemoved, "", c.String(), ""})
+		}
+	}
+	for _, c := range new.Constraints {
+		if c.IsDirective() && !slices.Contains(old.Constraints, c) {
+			changes = append(changes, Change{ChangeAdded, "", "", c.String()})
+		}
+	}
+	return
+}
+
+// describedExpression returns the expression of the definition followed by
+// its comments, as a trailing comment would be written
+func describedExpression(def Definition) string {
+	if len(def.Comments) == 0 {
+		return def.Expression()
+	}
+	return def.Expression() + " ; " + strings.Join(def.Comments, " ")
+}
+
+// Equal tells whether the two specs are structurally the same: their
+// definitions have the same regexps, length bounds and comments, their
+// filenames are declared alike and they share the same constraints and
+// options. The order of the declarations is ignored.
+func (s Synta) Equal(other Synta) bool {
+	if len(s.Definitions) != len(other.Definitions) {
+		return false
+	}
+	for id, def := range s.Definitions {
+		otherDef, ok := other.Definitions[id]
+		if !ok || def.Expression() != otherDef.Expression() || !slices.Equal(def.Comments, otherDef.Comments) {
+			return false
+		}
+	}
+
+	if len(s.Alternatives) != len(other.Alternatives) {
+		return false
+	}
+	filenames := s.filenames()
+	for i, f := range other.filenames() {
+		if filenames[i].String() != f.String() ||
+			!slices.Equal(filenames[i].Comments, f.Comments) ||
+			!slices.Equal(filenames[i].ExtensionComments, f.ExtensionComments) {
+			return false
+		}
+	}
+
+	if len(s.Constraints) != len(other.Constraints) {
+		return false
+	}
+	for _, c := range s.Constraints {
+		if !slices.Contains(other.Constraints, c) {
+			return false
+		}
+	}
+	return s.key == other.key && s.LowercaseInput == other.LowercaseInput && s.MatchStrategy == other.MatchStrategy && s.separator() == other.separator()
+}
@@ -0,0 +1,192 @@
+package synta
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffWithChangedAndAddedDefinitions(t *testing.T) {
+	old := MustSynta(`name = [a-z]+
+ext = pdf
+> name.ext`)
+	new := MustSynta(`ext = pdf|txt
+name = [a-z]+
+year = [0-9]{4}
+> name-year.ext`)
+
+	changes := Diff(old, new)
+	assert.Equal(t, []Change{
+		{ChangeModified, "ext", "pdf", "pdf|txt"},
+		{ChangeAdded, "year", "", "[0-9]{4}"},
+		{ChangeModified, "", "name.ext", "name-year.ext"},
+	}, changes)
+	assert.Equal(t, "~ `ext`: pdf -> pdf|txt", changes[0].String())
+	assert.Equal(t, "+ `year`: [0-9]{4}", changes[1].String())
+	assert.Equal(t, "~ filename: name.ext -> name-year.ext", changes[2].String())
+}
+
+func TestDiffWithReorderedDefinitions(t *testing.T) {
+	old := MustSynta(`name = [a-z]+
+ext = pdf
+> name.ext`)
+	new := MustSynta(`ext = pdf
+name = [a-z]+
+> name.ext`)
+
+	assert.Empty(t, Diff(old, new))
+}
+
+func TestDiffWithChangedSeparator(t *testing.T) {
+	old := MustSynta("name = [a-z]+\next = pdf\n> name-name.ext")
+	new := MustSynta("name = [a-z]+\next = pdf\n! separator = _\n> name-name.ext")
+
+	changes := Diff(old, new)
+	assert.Equal(t, []Change{{ChangeModified, "", "! separator = -", "! separator = _"}}, changes)
+	assert.Equal(t, "~ filename: ! separator = - -> ! separator = _", changes[0].String())
+}
+
+func TestDiffWithChangedKey(t *testing.T) {
+	spec := func(directive string) Synta {
+		return MustSynta("id = [0-9]+\nname = [a-z]+\next = pdf\n" + directive + "> id-name.ext")
+	}
+
+	assert.Equal(t, []Change{{ChangeModified, "", "! key = id", "! key = name"}}, Diff(spec("! key = id\n"), spec("! key = name\n")))
+	assert.Equal(t, []Change{{ChangeRemoved, "", "! key = id", ""}}, Diff(spec("! key = id\n"), spec("")))
+	assert.Equal(t, []Change{{ChangeAdded, "", "", "! key = name"}}, Diff(spec(""), spec("! key = name\n")))
+	assert.Empty(t, Diff(spec("! key = id\n"), spec("! key = id\n")))
+}
+
+func TestDiffWithChangedConstraints(t *testing.T) {
+	old := MustSynta(`name = [a-z]+
+tag = [a-z]+
+author = [a-z]+
+ext = pdf
+! require tag unless author
+> name(-tag)?(-author)?.ext`)
+	new := MustSynta(`name = [a-z]+
+tag = [a-z]+
+author = [a-z]+
+ext = pdf
+> name(-tag)?(-author)?.ext`)
+
+	changes := Diff(old, new)
+	assert.Equal(t, []Change{{ChangeRemoved, "", "! require tag unless author", ""}}, changes)
+	assert.Equal(t, "- filename: ! require tag unless author", changes[0].String())
+	assert.Equal(t, []Change{{ChangeAdded, "", "", "! require tag unless author"}}, Diff(new, old))
+	assert.Empty(t, Diff(old, old))
+}
+
+func TestDiffWithRemovedDefinition(t *testing.T) {
+	old := MustSynta(`name = [a-z]+
+unused = a|b
+ext = pdf
+> name.ext`)
+	new := MustSynta(`name = [a-z]+
+ext = pdf
+> name.ext`)
+
+	assert.Equal(t, []Change{{ChangeRemoved, "unused", "a|b", ""}}, Diff(old, new))
+}
+
+func TestDiffWithChangedComments(t *testing.T) {
+	old := MustSynta(`; the format
+ext = pdf
+name = [a-z]+
+> name.ext`)
+	new := MustSynta(`ext = pdf ; the formats
+; the name
+name = [a-z]+
+> name.ext`)
+
+	changes := Diff(old, new)
+	assert.Equal(t, []Change{
+		{ChangeModified, "ext", "pdf ; the format", "pdf ; the formats"},
+		{ChangeModified, "name", "[a-z]+", "[a-z]+ ; the name"},
+	}, changes)
+	assert.Equal(t, "~ `name`: [a-z]+ -> [a-z]+ ; the name", changes[1].String())
+}
+
+func TestDiffWithChangedOptional(t *testing.T) {
+	old := MustSynta(`name = [a-z]+
+tag = [a-z]+
+author = [a-z]+
+ext = pdf
+> name(-tag)?.ext`)
+	new := MustSynta(`name = [a-z]+
+tag = [a-z]+
+author = [a-z]+
+ext = pdf
+> name(-tag(-author)?)?.ext`)
+
+	assert.Equal(t, []Change{{ChangeModified, "", "name(-tag)?.ext", "name(-tag(-author)?)?.ext"}}, Diff(old, new))
+}
+
+func TestEqual(t *testing.T) {
+	synta := MustSynta(`; the name
+name = [a-z]+
+year = [0-9]{4}
+ext = pdf
+! require year unless name
+> name(-year)?.ext`)
+	reordered := MustSynta(`ext = pdf
+! require year unless name
+> name(-year)?.ext
+year = [0-9]{4}
+; the name
+name = [a-z]+`)
+	assert.True(t, synta.Equal(reordered))
+	assert.True(t, reordered.Equal(synta))
+
+	required := MustSynta(`; the name
+name = [a-z]+
+year = [0-9]{4}
+ext = pdf
+! require year unless name
+> name-year.ext`)
+	assert.False(t, synta.Equal(required))
+
+	uncommented := MustSynta(`name = [a-z]+
+year = [0-9]{4}
+ext = pdf
+! require year unless name
+> name(-year)?.ext`)
+	assert.False(t, synta.Equal(uncommented))
+
+	bounded := MustSynta(`; the name
+name = [a-z]+ len(1,8)
+year = [0-9]{4}
+ext = pdf
+! require year unless name
+> name(-year)?.ext`)
+	assert.False(t, synta.Equal(bounded))
+}
+
+func TestDiffWithAlternatives(t *testing.T) {
+	opts := Options{MultipleFilenames: true}
+	old, err := ParseSyntaWithOptions(`name = [a-z]+
+year = [0-9]{4}
+ext = pdf
+> name-year.ext
+> name.ext`, opts)
+	assert.Nil(t, err)
+	new, err := ParseSyntaWithOptions(`name = [a-z]+
+year = [0-9]{4}
+ext = pdf
+> name-year.ext
+> year-name.ext
+> name.ext`, opts)
+	assert.Nil(t, err)
+
+	changes := Diff(old, new)
+	assert.Equal(t, []Change{
+		{ChangeModified, "", "name.ext", "year-name.ext"},
+		{ChangeAdded, "", "", "name.ext"},
+	}, changes)
+	assert.Equal(t, "+ filename: name.ext", changes[1].String())
+
+	assert.Equal(t, []Change{
+		{ChangeModified, "", "year-name.ext", "name.ext"},
+		{ChangeRemoved, "", "name.ext", ""},
+	}, Diff(new, old))
+}
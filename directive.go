@@ -0,0 +1,198 @@
+package synta
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+type ConstraintKind uint
+
+const (
+	// ConstraintRequiredUnless requires the Subject to be present in the
+	// filename unless the Other identifier is
+	ConstraintRequiredUnless ConstraintKind = iota
+	// ConstraintEqual requires every segment of the Subject to have the same
+	// value. It is declared inline by a backreference segment `=subject`.
+	ConstraintEqual
+)
+
+var constraintKindNames = []string{
+	ConstraintRequiredUnless: "RequiredUnless",
+	ConstraintEqual:          "Equal",
+}
+
+func (k ConstraintKind) String() string {
+	if int(k) < len(constraintKindNames) {
+		return constraintKindNames[k]
+	}
+	return fmt.Sprintf("ConstraintKind(%d)", uint(k))
+}
+
+// MarshalText encodes the kind by name, such as "RequiredUnless"
+func (k ConstraintKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText decodes the kind from its name, failing on unknown kinds
+func (k *ConstraintKind) UnmarshalText(text []byte) error {
+	i := slices.Index(constraintKindNames, string(text))
+	if i < 0 {
+		return fmt.Errorf("unknown constraint kind `%s`", text)
+	}
+	*k = ConstraintKind(i)
+	return nil
+}
+
+// A Constraint is a rule on the values of a filename which cannot be
+// expressed by the filename grammar alone. It is declared with a directive
+// line starting with "!", e.g.:
+//
+//	! require subject unless other
+type Constraint struct {
+	Kind    ConstraintKind `json:"kind"`
+	Subject Identifier     `json:"subject"`
+	Other   Identifier     `json:"other,omitempty"`
+}
+
+// String returns the directive declaring the constraint
+func (c Constraint) String() string {
+	switch c.Kind {
+	case ConstraintRequiredUnless:
+		return fmt.Sprintf("! require %s unless %s", c.Subject, c.Other)
+	case ConstraintEqual:
+		return "=" + string(c.Subject)
+	}
+	return ""
+}
+
+// IsDirective tells whether the constraint is declared by a directive line,
+// rather than inline in the filename
+func (c Constraint) IsDirective() bool {
+	return c.Kind != ConstraintEqual
+}
+
+// check returns an error describing the violation of the constraint by the
+// given captures, which hold every value extracted for each identifier
+func (c Constraint) check(captures map[Identifier][]string) (err error) {
+	switch c.Kind {
+	case ConstraintRequiredUnless:
+		_, hasSubject := captures[c.Subject]
+		_, hasOther := captures[c.Other]
+		if !hasSubject && !hasOther {
+			err = fmt.Errorf("`%s` is required unless `%s` is present", c.Subject, c.Other)
+		}
+	case ConstraintEqual:
+		values := captures[c.Subject]
+		for _, value := range values {
+			if value != values[0] {
+				err = fmt.Errorf("every `%s` must have the same value, found `%s` and `%s`", c.Subject, values[0], value)
+				return
+			}
+		}
+	}
+	return
+}
+
+func (s Synta) checkConstraints(values map[Identifier][]string) (err error) {
+	for _, c := range s.Constraints {
+		// the constraints on identifiers used only by the alternatives do
+		// not apply to the filename
+		if !s.Filename.uses(c.Subject) && !s.Filename.uses(c.Other) {
+			continue
+		}
+		if err = c.check(values); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// checkLengths ensures that every captured value respects the length bounds
+// of its definition
+func (s Synta) checkLengths(values map[Identifier][]string) (err error) {
+	for id, captured := range values {
+		for _, value := range captured {
+			if e := s.Definitions[id].checkLength(value); e != nil {
+				return fmt.Errorf("invalid `%s`: %v", id, e)
+			}
+		}
+	}
+	return
+}
+
+// checkCaptures ensures that the captured values respect both the length
+// bounds of the definitions and the constraints of the spec
+func (s Synta) checkCaptures(values map[Identifier][]string) (err error) {
+	if err = s.checkLengths(values); err != nil {
+		return
+	}
+	return s.checkConstraints(values)
+}
+
+// parseDirective parses a line starting with "!" and applies it to the Synta
+// structure. Directives can only reference identifiers used by one of the
+// filenames, so they must be parsed after them.
+func parseDirective(s *Synta, line string) (err error) {
+	fields := strings.Fields(line[1:])
+	if len(fields) == 0 {
+		return fmt.Errorf("empty directive: %s", line)
+	}
+
+	switch fields[0] {
+	case "require":
+		if len(fields) != 4 || fields[2] != "unless" {
+			return fmt.Errorf("invalid directive, expected `! require <id> unless <id>`: %s", line)
+		}
+		c := Constraint{ConstraintRequiredUnless, Identifier(fields[1]), Identifier(fields[3])}
+		for _, id := range []Identifier{c.Subject, c.Other} {
+			if !s.uses(id) {
+				return fmt.Errorf("directive references `%s`, which is not part of the filename: %s", id, line)
+			}
+		}
+		s.Constraints = append(s.Constraints, c)
+	case "key":
+		if len(fields) != 3 || fields[1] != "=" {
+			return fmt.Errorf("invalid directive, expected `! key = <id>`: %s", line)
+		}
+		if s.key != "" {
+			return fmt.Errorf("the key is declared twice: %s", line)
+		}
+		if id := Identifier(fields[2]); !s.uses(id) {
+			return fmt.Errorf("directive references `%s`, which is not part of the filename: %s", id, line)
+		}
+		s.key = Identifier(fields[2])
+	case "separator":
+		if len(fields) != 3 || fields[1] != "=" {
+			return fmt.Errorf("invalid directive, expected `! separator = <sep>`: %s", line)
+		}
+		if s.Separator != "" {
+			return fmt.Errorf("the separator is declared twice: %s", line)
+		}
+		if strings.ContainsAny(fields[2], ".\\") || strings.IndexFunc(fields[2], isLetter) >= 0 {
+			return fmt.Errorf("the separator cannot contain letters, dots or backslashes: %s", line)
+		}
+		s.Separator = fields[2]
+	default:
+		err = fmt.Errorf("unknown directive `%s`: %s", fields[0], line)
+	}
+	return
+}
+
+// Key returns the identifier declared as the key of the spec with the
+// `! key = <id>` directive, which identifies the entity a filename refers to,
+// see SameEntity
+func (s Synta) Key() (key Identifier, ok bool) {
+	return s.key, s.key != ""
+}
+
+// uses tells if the identifier appears anywhere in the segments of the
+// filename or of its alternatives
+func (s Synta) uses(id Identifier) bool {
+	return slices.ContainsFunc(s.filenames(), func(f Filename) bool { return f.uses(id) })
+}
+
+// uses tells if the identifier appears anywhere in the filename segments
+func (f Filename) uses(id Identifier) bool {
+	return slices.Contains(getAllIdentifiers(f.Segments), id)
+}
@@ -0,0 +1,212 @@
+package synta
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const requireUnlessInput = `name = [a-z]+
+num = [0-9]+
+tag = [a-z]+
+ext = pdf
+! require tag unless num
+> name(-num)?(-tag)?.ext`
+
+func TestParseRequireUnlessDirective(t *testing.T) {
+	synta, err := ParseSynta(requireUnlessInput)
+	assert.Nil(t, err)
+	assert.Equal(t, []Constraint{{ConstraintRequiredUnless, "tag", "num"}}, synta.Constraints)
+	assert.Equal(t, "! require tag unless num", synta.Constraints[0].String())
+}
+
+func TestExtractWithRequireUnless(t *testing.T) {
+	synta, err := ParseSynta(requireUnlessInput)
+	assert.Nil(t, err)
+
+	_, err = synta.Extract("lesson.pdf")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "`tag` is required unless `num` is present")
+
+	_, err = synta.Extract("lesson-01.pdf")
+	assert.Nil(t, err)
+	_, err = synta.Extract("lesson-intro.pdf")
+	assert.Nil(t, err)
+	_, err = synta.Extract("lesson-01-intro.pdf")
+	assert.Nil(t, err)
+}
+
+func TestParseInvalidDirectives(t *testing.T) {
+	inputs := []string{
+		"! require tag\n",
+		"! require tag unless missing\n",
+		"! forbid tag\n",
+		"!\n",
+	}
+	for _, directive := range inputs {
+		_, err := ParseSynta(`name = [a-z]+
+num = [0-9]+
+tag = [a-z]+
+ext = pdf
+` + directive + `> name(-num)?(-tag)?.ext`)
+		assert.NotNil(t, err, directive)
+	}
+}
+
+func TestParseKeyDirective(t *testing.T) {
+	synta, err := ParseSynta(`id = [0-9]+
+name = [a-z]+
+ext = pdf
+! key = id
+> id-name.ext`)
+	assert.Nil(t, err)
+	key, ok := synta.Key()
+	assert.True(t, ok)
+	assert.Equal(t, Identifier("id"), key)
+
+	synta, err = ParseSynta(requireUnlessInput)
+	assert.Nil(t, err)
+	_, ok = synta.Key()
+	assert.False(t, ok)
+}
+
+func TestParseInvalidKeyDirectives(t *testing.T) {
+	inputs := []string{
+		"! key = author\n",
+		"! key = ext\n",
+		"! key id\n",
+		"! key = id\n! key = name\n",
+	}
+	for _, directive := range inputs {
+		_, err := ParseSynta(`id = [0-9]+
+name = [a-z]+
+ext = pdf
+` + directive + `> id-name.ext`)
+		assert.NotNil(t, err, directive)
+	}
+}
+
+func TestParseDirectivesWithAlternatives(t *testing.T) {
+	synta, err := ParseSyntaWithOptions(`id = [0-9]+
+name = [a-z]+
+tag = [a-z]+
+author = [a-z]+
+ext = pdf
+! key = id
+! require tag unless author
+> name.ext
+> id(-tag)?(-author)?.ext`, Options{MultipleFilenames: true})
+	assert.Nil(t, err)
+	key, _ := synta.Key()
+	assert.Equal(t, Identifier("id"), key)
+
+	// the constraint only applies to the filenames using its identifiers
+	matches, err := synta.Match("lesson.pdf")
+	assert.Nil(t, err)
+	assert.True(t, matches)
+	matches, err = synta.Match("42-intro.pdf")
+	assert.Nil(t, err)
+	assert.True(t, matches)
+	matches, err = synta.Match("42.pdf")
+	assert.Nil(t, err)
+	assert.False(t, matches)
+}
+
+const backreferenceInput = `code = [A-Z]{3}
+name = [a-z]+
+ext = pdf
+> code-name-=code.ext`
+
+func TestParseBackreference(t *testing.T) {
+	synta, err := ParseSynta(backreferenceInput)
+	assert.Nil(t, err)
+
+	code := Identifier("code")
+	assert.Equal(t, Segment{SegmentTypeBackreference, &code, nil, nil}, synta.Filename.Segments[2])
+	assert.Equal(t, []Constraint{{Kind: ConstraintEqual, Subject: "code"}}, synta.Constraints)
+	assert.Equal(t, "code-name-=code.ext", synta.Filename.String())
+}
+
+func TestExtractWithBackreference(t *testing.T) {
+	synta, err := ParseSynta(backreferenceInput)
+	assert.Nil(t, err)
+
+	values, err := synta.Extract("ABC-notes-ABC.pdf")
+	assert.Nil(t, err)
+	assert.Equal(t, map[Identifier]string{"code": "ABC", "name": "notes", "ext": "pdf"}, values)
+
+	_, err = synta.Extract("ABC-notes-XYZ.pdf")
+	assert.NotNil(t, err)
+	assert.Contains(t, err.Error(), "every `code` must have the same value, found `ABC` and `XYZ`")
+}
+
+func TestExtractWithOptionalBackreference(t *testing.T) {
+	synta, err := ParseSynta(`code = [A-Z]{3}
+ext = pdf
+> code(-=code)?.ext`)
+	assert.Nil(t, err)
+
+	_, err = synta.Extract("ABC.pdf")
+	assert.Nil(t, err)
+	_, err = synta.Extract("ABC-ABC.pdf")
+	assert.Nil(t, err)
+	_, err = synta.Extract("ABC-XYZ.pdf")
+	assert.NotNil(t, err)
+}
+
+func TestParseInvalidBackreferences(t *testing.T) {
+	inputs := []string{
+		"> =code.ext",
+		"> name-=other.ext",
+		"> name-=.ext",
+		"> name-==name.ext",
+	}
+	for _, filename := range inputs {
+		_, err := ParseSynta(`code = [A-Z]{3}
+name = [a-z]+
+other = [a-z]+
+ext = pdf
+` + filename)
+		assert.NotNil(t, err, filename)
+	}
+}
+
+func TestParseSeparatorDirective(t *testing.T) {
+	input := `type = lesson
+number = [0-9]{2}
+title = [a-z-]+
+ext = pdf
+! separator = _
+> type-number(-title)?.ext`
+	synta, err := ParseSynta(input)
+	assert.Nil(t, err)
+	assert.Equal(t, "_", synta.Separator)
+
+	values, err := synta.Extract("lesson_01_intro-to-go.pdf")
+	assert.Nil(t, err)
+	assert.Equal(t, map[Identifier]string{"type": "lesson", "number": "01", "title": "intro-to-go", "ext": "pdf"}, values)
+	for filename, expected := range map[string]bool{
+		"lesson_01.pdf":       true,
+		"lesson_01_intro.pdf": true,
+		"lesson-01.pdf":       false,
+		"lesson-01-intro.pdf": false,
+	} {
+		matches, err := synta.Match(filename)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, matches, filename)
+	}
+
+	filename, err := synta.Render(map[Identifier]string{"type": "lesson", "number": "02", "title": "end", "ext": "pdf"})
+	assert.Nil(t, err)
+	assert.Equal(t, "lesson_02_end.pdf", filename)
+	example, err := synta.Example()
+	assert.Nil(t, err)
+	assert.Contains(t, example, "_")
+	assert.Contains(t, synta.String(), "! separator = _\n")
+	assert.True(t, MustSynta(synta.String()).Equal(synta))
+
+	for _, directive := range []string{"! separator = _\n! separator = +", "! separator = x", "! separator _"} {
+		_, err = ParseSynta("name = [a-z]+\n" + directive + "\n> name.name")
+		assert.NotNil(t, err, directive)
+	}
+}
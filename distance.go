@@ -0,0 +1,110 @@
+package synta
+
+import (
+	"errors"
+	"regexp/syntax"
+)
+
+// Distance returns the edit distance between the filename and the closest
+// filename matched by the regexp of the spec, that is the minimum number of
+// characters to insert, delete or replace for the filename to match it. A
+// matching filename has distance zero. The constraints of the spec, such as
+// backreferences and length bounds, are not taken into account.
+func (s Synta) Distance(filename string) (distance int, err error) {
+	expr, err := s.BuildRegexp()
+	if err != nil {
+		return
+	}
+	re, err := syntax.Parse(expr.String(), syntax.Perl)
+	if err != nil {
+		return
+	}
+	prog, err := syntax.Compile(re.Simplify())
+	if err != nil {
+		return
+	}
+
+	distance, ok := editDistance(prog, []rune(s.input(filename)))
+	if !ok {
+		err = errors.New("the spec matches no filename")
+	}
+	return
+}
+
+// editDistance computes the edit distance between the input and the language
+// of the program, by finding the cheapest path through the pairs of program
+// instruction and input position. Following an instruction on the matching
+// character costs nothing, while replacing, deleting or inserting a character
+// costs one. Edges cost either zero or one, so a deque is enough to visit the
+// pairs in order of cost. The boolean is false when the language is empty.
+func editDistance(prog *syntax.Prog, input []rune) (distance int, ok bool) {
+	type state struct{ pc, pos int }
+
+	costs := map[state]int{}
+	deque := []state{{prog.Start, 0}}
+	costs[deque[0]] = 0
+	visit := func(from state, to state, cost int) {
+		total := costs[from] + cost
+		if known, ok := costs[to]; ok && known <= total {
+			return
+		}
+		costs[to] = total
+		if cost == 0 {
+			deque = append([]state{to}, deque...)
+		} else {
+			deque = append(deque, to)
+		}
+	}
+
+	done := map[state]bool{}
+	for len(deque) > 0 {
+		current := deque[0]
+		deque = deque[1:]
+		if done[current] {
+			continue
+		}
+		done[current] = true
+
+		inst := prog.Inst[current.pc]
+		if current.pos < len(input) {
+			// delete the current character
+			visit(current, state{current.pc, current.pos + 1}, 1)
+		}
+
+		switch inst.Op {
+		case syntax.InstMatch:
+			if current.pos == len(input) {
+				return costs[current], true
+			}
+		case syntax.InstAlt, syntax.InstAltMatch:
+			visit(current, state{int(inst.Out), current.pos}, 0)
+			visit(current, state{int(inst.Arg), current.pos}, 0)
+		case syntax.InstCapture, syntax.InstNop:
+			visit(current, state{int(inst.Out), current.pos}, 0)
+		case syntax.InstEmptyWidth:
+			op := syntax.EmptyOp(inst.Arg)
+			if op&syntax.EmptyBeginText != 0 && current.pos != 0 ||
+				op&syntax.EmptyEndText != 0 && current.pos != len(input) {
+				continue
+			}
+			visit(current, state{int(inst.Out), current.pos}, 0)
+		case syntax.InstRune, syntax.InstRune1, syntax.InstRuneAny, syntax.InstRuneAnyNotNL:
+			if inst.Op == syntax.InstRune && len(inst.Rune) == 0 {
+				// an empty character class matches no character
+				continue
+			}
+			next := int(inst.Out)
+			if current.pos < len(input) {
+				if inst.MatchRune(input[current.pos]) {
+					visit(current, state{next, current.pos + 1}, 0)
+				} else {
+					// replace the current character
+					visit(current, state{next, current.pos + 1}, 1)
+				}
+			}
+			// insert a character
+			visit(current, state{next, current.pos}, 1)
+		}
+	}
+	return
+}
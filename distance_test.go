@@ -0,0 +1,37 @@
+package synta
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDistance(t *testing.T) {
+	synta, err := ParseSynta(extractInput)
+	assert.Nil(t, err)
+
+	distance, err := synta.Distance("analisi-2024-esame.pdf")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, distance)
+
+	distance, err = synta.Distance("analisi-202-esame.pdf")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, distance)
+
+	distance, err = synta.Distance("Analisi-2024.pdf")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, distance)
+
+	distance, err = synta.Distance("analisi_2024.doc")
+	assert.Nil(t, err)
+	assert.Equal(t, 4, distance)
+}
+
+func TestDistanceWithEmptyLanguage(t *testing.T) {
+	synta := MustSynta(`name = [^\x00-\x{10FFFF}]
+ext = pdf
+> name.ext`)
+
+	_, err := synta.Distance("a.pdf")
+	assert.NotNil(t, err)
+}
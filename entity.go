@@ -0,0 +1,32 @@
+package synta
+
+import (
+	"fmt"
+	"slices"
+)
+
+// SameEntity tells whether two filenames refer to the same entity, that is
+// whether they have the same value for the key identifier, regardless of
+// their other segments. Both filenames must match the spec. A filename
+// lacking the key, because it belongs to an absent optional segment, is
+// never the same entity as another one.
+func (s Synta) SameEntity(a, b string, key Identifier) (same bool, err error) {
+	if !slices.Contains(s.Filename.identifiers(), key) {
+		err = fmt.Errorf("`%s` is not part of the filename", key)
+		return
+	}
+
+	valuesA, err := s.Extract(a)
+	if err != nil {
+		return
+	}
+	valuesB, err := s.Extract(b)
+	if err != nil {
+		return
+	}
+
+	keyA, okA := valuesA[key]
+	keyB, okB := valuesB[key]
+	same = okA && okB && keyA == keyB
+	return
+}
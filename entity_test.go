@@ -0,0 +1,38 @@
+package synta
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSameEntity(t *testing.T) {
+	synta := MustSynta(`id = [0-9]+
+name = [a-z]+
+version = v[0-9]+
+ext = pdf
+> id-name(-version)?.ext`)
+
+	same, err := synta.SameEntity("42-report.pdf", "42-report-v2.pdf", "id")
+	assert.Nil(t, err)
+	assert.True(t, same)
+
+	same, err = synta.SameEntity("42-report.pdf", "43-report.pdf", "id")
+	assert.Nil(t, err)
+	assert.False(t, same)
+
+	same, err = synta.SameEntity("42-report.pdf", "43-report.pdf", "version")
+	assert.Nil(t, err)
+	assert.False(t, same)
+}
+
+func TestSameEntityWithInvalidInput(t *testing.T) {
+	synta := MustSynta(`id = [0-9]+
+ext = pdf
+> id.ext`)
+
+	_, err := synta.SameEntity("42.pdf", "report.pdf", "id")
+	assert.NotNil(t, err)
+	_, err = synta.SameEntity("42.pdf", "42.pdf", "name")
+	assert.EqualError(t, err, "`name` is not part of the filename")
+}
@@ -0,0 +1,73 @@
+package synta
+
+import (
+	"regexp/syntax"
+)
+
+// finiteStrings returns every string matched by the given pattern, provided
+// the pattern matches a finite number of strings, at most limit. The boolean
+// is false when the language of the pattern is infinite, too big or uses
+// constructs which are not supported (such as case folding).
+func finiteStrings(pattern string, limit int) (strings []string, ok bool) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return
+	}
+	return expand(re.Simplify(), limit)
+}
+
+func expand(re *syntax.Regexp, limit int) (strings []string, ok bool) {
+	switch re.Op {
+	case syntax.OpEmptyMatch:
+		return []string{""}, true
+	case syntax.OpLiteral:
+		if re.Flags&syntax.FoldCase != 0 {
+			return
+		}
+		return []string{string(re.Rune)}, true
+	case syntax.OpCharClass:
+		for i := 0; i < len(re.Rune); i += 2 {
+			for r := re.Rune[i]; r <= re.Rune[i+1]; r++ {
+				if len(strings) >= limit {
+					return nil, false
+				}
+				strings = append(strings, string(r))
+			}
+		}
+		return strings, true
+	case syntax.OpCapture:
+		return expand(re.Sub[0], limit)
+	case syntax.OpQuest:
+		sub, ok := expand(re.Sub[0], limit-1)
+		if !ok {
+			return nil, false
+		}
+		return append([]string{""}, sub...), true
+	case syntax.OpAlternate:
+		for _, sub := range re.Sub {
+			alt, ok := expand(sub, limit-len(strings))
+			if !ok {
+				return nil, false
+			}
+			strings = append(strings, alt...)
+		}
+		return strings, true
+	case syntax.OpConcat:
+		strings = []string{""}
+		for _, sub := range re.Sub {
+			suffixes, ok := expand(sub, limit)
+			if !ok || len(strings)*len(suffixes) > limit {
+				return nil, false
+			}
+			product := []string{}
+			for _, prefix := range strings {
+				for _, suffix := range suffixes {
+					product = append(product, prefix+suffix)
+				}
+			}
+			strings = product
+		}
+		return strings, true
+	}
+	return
+}
@@ -0,0 +1,39 @@
+package synta
+
+import (
+	"errors"
+)
+
+// A SyntaError is an error found at a known position of a Synta file. Line
+// is 1-based, while Pos is the 0-based byte offset within the line, as for a
+// Token. Token is the type of the offending token, TokenError when it could
+// not be recognized. Callers can retrieve it with errors.As.
+type SyntaError struct {
+	Line  int
+	Pos   int
+	Msg   string
+	Token TokenType
+}
+
+func (e SyntaError) Error() string {
+	return e.Msg
+}
+
+// origin records where a trimmed line of a Synta file comes from
+type origin struct {
+	line   int
+	indent int
+}
+
+// locate places an error found in the trimmed line at the position of the
+// line within the file. Errors other than SyntaError point to the start of
+// the line.
+func (o origin) locate(err error) error {
+	var se SyntaError
+	if !errors.As(err, &se) {
+		se = SyntaError{Msg: err.Error(), Token: TokenError}
+	}
+	se.Line = o.line
+	se.Pos += o.indent
+	return se
+}
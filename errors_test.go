@@ -0,0 +1,55 @@
+package synta
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyntaError(t *testing.T) {
+	_, err := ParseSynta("name = [a-z]+\n\n  year = [0-9\n> name-year.name")
+	var se SyntaError
+	assert.True(t, errors.As(err, &se))
+	assert.Equal(t, 3, se.Line)
+	assert.Equal(t, 9, se.Pos)
+	assert.Equal(t, TokenPattern, se.Token)
+	assert.Equal(t, "error parsing regexp: missing closing ]: `[0-9`", err.Error())
+
+	_, err = ParseSynta("name = [a-z]+\n; the filename\n> name-year.name")
+	assert.True(t, errors.As(err, &se))
+	assert.Equal(t, SyntaError{3, 7, "missing definition for `year`", TokenIdentifier}, se)
+
+	_, err = ParseSynta("name = [a-z]+\n> name_x.name")
+	assert.True(t, errors.As(err, &se))
+	assert.Equal(t, 2, se.Line)
+	assert.Equal(t, 6, se.Pos)
+	assert.Equal(t, TokenError, se.Token)
+	assert.Equal(t, "Invalid char at column 5:\nname_x.name\n    ^\nexpected either a char, or a -, or a ( or a .", err.Error())
+
+	_, err = ParseSynta("name = [a-z]+\n  Year2 = [0-9]+\n> name.name")
+	assert.True(t, errors.As(err, &se))
+	assert.Equal(t, SyntaError{2, 6, "Invalid identifier: Year2", TokenIdentifier}, se)
+
+	_, err = ParseSynta("name = [a-z]+\nyear2 = [0-9]+\n> name.name")
+	assert.True(t, errors.As(err, &se))
+	assert.Equal(t, 2, se.Line)
+	assert.Equal(t, 4, se.Pos)
+
+	l := NewLexer("name = [a-z]+\n> name_x.ext", Options{})
+	for err = nil; err == nil; {
+		_, err = l.NextToken()
+	}
+	assert.True(t, errors.As(err, &se))
+	assert.Equal(t, SyntaError{2, 6, "unexpected character `_` at line 2, column 6", TokenError}, se)
+}
+
+func TestSyntaErrorWithDuplicateDefinition(t *testing.T) {
+	_, err := ParseSynta("name = [a-z]+\n; the year\nyear = [0-9]{4}\n> name-year.name\n\nyear = [0-9]+")
+	var se SyntaError
+	assert.True(t, errors.As(err, &se))
+	assert.Equal(t, SyntaError{6, 0, "defintion for `year` is provided twice, at lines 3 and 6", TokenIdentifier}, se)
+
+	s := MustSynta("name = [a-z]+\n\n> name.name")
+	assert.Equal(t, []int{1, 3}, []int{s.Nodes[0].Line, s.Nodes[1].Line})
+}
@@ -0,0 +1,51 @@
+package synta
+
+import (
+	"slices"
+	"strings"
+)
+
+// Extensions returns every extension accepted by the spec, its alternatives
+// included, in the order in which its definition declares them. It returns
+// nil when the definition of the extension accepts infinitely many values, or
+// more than can be listed.
+func (s Synta) Extensions() (extensions []Identifier) {
+	extensions = []Identifier{}
+	ids := []Identifier{}
+	for _, f := range s.filenames() {
+		ids = append(ids, f.extensions()...)
+	}
+	for _, id := range ids {
+		def, ok := s.Definitions[id]
+		if !ok {
+			return nil
+		}
+		values, ok := finiteStrings(def.Source(), maxEnumeratedExtensions)
+		if !ok {
+			return nil
+		}
+		for _, value := range values {
+			if def.checkLength(value) == nil && !slices.Contains(extensions, Identifier(value)) {
+				extensions = append(extensions, Identifier(value))
+			}
+		}
+	}
+	return
+}
+
+// extensionSource returns the regexp of the extension, joining the ones of an
+// alternation of extensions, or an empty one for a filename without
+// extension. The length bounds of the definitions are not taken into account.
+func (s Synta) extensionSource() string {
+	if !s.Filename.HasExtension() {
+		return ""
+	}
+	if len(s.Filename.Extensions) == 0 {
+		return s.Definitions[s.Filename.Extension].Source()
+	}
+	sources := []string{}
+	for _, ext := range s.Filename.Extensions {
+		sources = append(sources, "(?:"+s.Definitions[ext].Source()+")")
+	}
+	return strings.Join(sources, "|")
+}
@@ -0,0 +1,39 @@
+package synta
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtensions(t *testing.T) {
+	synta := MustSynta(`name = [a-z]+
+ext = (md|txt)
+> name.ext`)
+	assert.Equal(t, []Identifier{"md", "txt"}, synta.Extensions())
+
+	synta = MustSynta(`name = [a-z]+
+ext = pdf
+> name.ext`)
+	assert.Equal(t, []Identifier{"pdf"}, synta.Extensions())
+
+	synta = MustSynta(`name = [a-z]+
+ext = t(xt|ex)|pdf
+> name.ext`)
+	assert.Equal(t, []Identifier{"txt", "tex", "pdf"}, synta.Extensions())
+
+	synta = MustSynta(`name = [a-z]+
+ext = [a-z]+
+> name.ext`)
+	assert.Nil(t, synta.Extensions())
+}
+
+func TestExtensionsWithAlternatives(t *testing.T) {
+	synta, err := ParseSyntaWithOptions(`name = [a-z]+
+doc = pdf
+text = md|txt
+> name.doc
+> name.text`, Options{MultipleFilenames: true})
+	assert.Nil(t, err)
+	assert.Equal(t, []Identifier{"pdf", "md", "txt"}, synta.Extensions())
+}
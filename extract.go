@@ -0,0 +1,547 @@
+package synta
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// BuildRegexp builds a single anchored regexp matching the whole filename.
+// Every identifier segment becomes a capture group named after its
+// identifier, inline segments become non-capturing groups and optional
+// segments non-capturing optional groups. Group names are sanitized to be
+// valid regexp group names, see GroupNames.
+func (s Synta) BuildRegexp() (expr *regexp.Regexp, err error) {
+	expr, _, err = s.buildRegexp()
+	return
+}
+
+// GroupNames returns the identifier captured by each named group of the
+// regexp built by BuildRegexp. Identifiers which are not valid group names,
+// because they contain characters other than ASCII letters, digits and
+// underscores or start with a digit, are sanitized and made unique.
+func (s Synta) GroupNames() (names map[string]Identifier, err error) {
+	_, names, err = s.buildRegexp()
+	return
+}
+
+func (s Synta) buildRegexp() (expr *regexp.Regexp, names map[string]Identifier, err error) {
+	groups := newGroupNamer()
+	expr, err = s.BuildRegexpWith(func(id Identifier, def Definition) string {
+		return "(?P<" + groups.name(id) + ">" + def.Source() + ")"
+	})
+	names = groups.names
+	return
+}
+
+// BuildRegexpWith works like BuildRegexp, but the pattern emitted for each
+// identifier segment, extension included, is produced by segmentFn. The
+// default behaviour wraps the definition's regexp in a group named after the
+// identifier. Separators and optional groups are emitted as usual.
+func (s Synta) BuildRegexpWith(segmentFn func(id Identifier, def Definition) string) (expr *regexp.Regexp, err error) {
+	pattern, err := buildSegments(s.Definitions, s.Filename.Segments, regexp.QuoteMeta(s.separator()), segmentFn)
+	if err != nil {
+		return
+	}
+	ext, err := s.extensionPattern(segmentFn)
+	if err != nil {
+		return
+	}
+
+	expr, err = regexp.Compile("^" + pattern + ext + "$")
+	return
+}
+
+// extensionPattern builds the pattern of the extension, dot included, with
+// segmentFn. An alternation of extensions matches any of them, while a
+// filename without extension yields an empty pattern.
+func (s Synta) extensionPattern(segmentFn func(id Identifier, def Definition) string) (pattern string, err error) {
+	branches := []string{}
+	for _, id := range s.Filename.extensions() {
+		def, ok := s.Definitions[id]
+		if !ok {
+			err = fmt.Errorf("missing definition for `%s`", id)
+			return
+		}
+		branches = append(branches, segmentFn(id, def))
+	}
+	if len(branches) == 0 {
+		return "", nil
+	}
+	if len(branches) == 1 {
+		return `\.` + branches[0], nil
+	}
+	return `\.(?:` + strings.Join(branches, "|") + `)`, nil
+}
+
+// Regexp returns a single anchored regexp matching the whole filename, made of
+// the regexps of the definitions without any capture group added. Unlike
+// BuildRegexp, its groups are only those of the definitions themselves.
+func (s Synta) Regexp() (expr *regexp.Regexp, err error) {
+	return s.BuildRegexpWith(func(id Identifier, def Definition) string {
+		return "(?:" + def.Source() + ")"
+	})
+}
+
+// leadingGroup tells whether the i-th segment is an optional or repeated
+// segment preceded only by such segments and followed by another one. Its
+// separator then follows it, rather than preceding it.
+func leadingGroup(segments []Segment, i int) bool {
+	for _, segment := range segments[:i+1] {
+		if !segment.ownsSeparator() {
+			return false
+		}
+	}
+	for _, segment := range segments[i+1:] {
+		if !segment.ownsSeparator() {
+			return true
+		}
+	}
+	return false
+}
+
+// buildSegments builds the pattern of the segments, sep being the pattern of
+// the separator
+func buildSegments(definitions map[Identifier]Definition, segments []Segment, sep string, segmentFn func(Identifier, Definition) string) (expr string, err error) {
+	for i, segment := range segments {
+		switch segment.Kind {
+		case SegmentTypeIdentifier, SegmentTypeBackreference:
+			def, ok := definitions[*segment.Value]
+			if !ok {
+				err = fmt.Errorf("missing definition for `%s`", *segment.Value)
+				return
+			}
+			expr += segmentFn(*segment.Value, def)
+		case SegmentTypeInline:
+			expr += "(?:" + segment.Inline.Source() + ")"
+		case SegmentTypeLiteral:
+			expr += regexp.QuoteMeta(string(*segment.Value))
+		case SegmentTypeOptional:
+			exp, e := buildSegments(definitions, segment.Subsegments, sep, segmentFn)
+			if e != nil {
+				err = e
+				return
+			}
+			if leadingGroup(segments, i) {
+				expr += "(?:" + exp + sep + ")?"
+				continue
+			}
+			expr += "(?:" + sep + exp + ")?"
+		case SegmentTypeRepeat:
+			exp, e := buildSegments(definitions, segment.Subsegments, sep, segmentFn)
+			if e != nil {
+				err = e
+				return
+			}
+			if leadingGroup(segments, i) {
+				expr += "(?:" + exp + sep + ")+"
+				continue
+			}
+			expr += "(?:" + sep + exp + ")+"
+		case SegmentTypeAlternation:
+			branches := []string{}
+			for _, branch := range segment.Subsegments {
+				exp, e := buildSegments(definitions, []Segment{branch}, sep, segmentFn)
+				if e != nil {
+					err = e
+					return
+				}
+				branches = append(branches, exp)
+			}
+			expr += "(?:" + strings.Join(branches, "|") + ")"
+		}
+
+		if separated(segments, i) {
+			expr += sep
+		}
+	}
+	return
+}
+
+// groupNamer assigns to each identifier a valid capture group name, which is
+// unique among the names it has assigned
+type groupNamer struct {
+	byIdentifier map[Identifier]string
+	names        map[string]Identifier
+}
+
+func newGroupNamer() *groupNamer {
+	return &groupNamer{map[Identifier]string{}, map[string]Identifier{}}
+}
+
+func (g *groupNamer) name(id Identifier) string {
+	if name, ok := g.byIdentifier[id]; ok {
+		return name
+	}
+
+	base := []rune{}
+	for _, r := range string(id) {
+		if r == '_' || r <= unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			base = append(base, r)
+		} else {
+			base = append(base, '_')
+		}
+	}
+	if len(base) == 0 || unicode.IsDigit(base[0]) {
+		base = append([]rune{'_'}, base...)
+	}
+
+	name := string(base)
+	for i := 2; ; i++ {
+		if _, taken := g.names[name]; !taken {
+			break
+		}
+		name = fmt.Sprintf("%s_%d", string(base), i)
+	}
+	g.byIdentifier[id] = name
+	g.names[name] = id
+	return name
+}
+
+// Extract matches a filename against the spec and returns the value captured
+// for each identifier. Identifiers belonging to optional segments which are
+// not present in the filename are omitted; when the filename is ambiguous,
+// the spec's MatchStrategy decides which ones are present. When an identifier
+// appears more than once, the first captured value is returned. A filename
+// violating one of the spec's constraints or the length bounds of a
+// definition is rejected. Each call compiles the regexps of the spec, one per
+// variant under a strategy other than MatchGreedy: Matcher compiles them once
+// for many filenames.
+func (s Synta) Extract(filename string) (values map[Identifier]string, err error) {
+	if s.MatchStrategy != MatchGreedy {
+		m, e := s.strategyMatcher()
+		if e != nil {
+			return nil, e
+		}
+		return m.extract(filename)
+	}
+
+	expr, names, err := s.buildRegexp()
+	if err != nil {
+		return
+	}
+
+	values, captures := captureValues(expr, names, s.input(filename))
+	if values == nil {
+		err = fmt.Errorf("filename `%s` does not match the spec", filename)
+		return
+	}
+
+	if err = s.checkCaptures(captures); err != nil {
+		values = nil
+		err = fmt.Errorf("filename `%s` does not match the spec: %v", filename, err)
+	}
+	return
+}
+
+// ExtractAll returns every distinct interpretation of the filename, one for
+// each way the optional segments can be chosen to match it, as Extract would
+// return them. An unambiguous filename yields a single interpretation.
+func (s Synta) ExtractAll(filename string) (all []map[Identifier]string, err error) {
+	for _, variant := range variants(s.Filename.Segments) {
+		expr, names, e := s.variantRegexp(variant)
+		if e != nil {
+			err = e
+			return
+		}
+
+		values, captures := captureValues(expr, names, s.input(filename))
+		if values == nil || s.checkCaptures(captures) != nil {
+			continue
+		}
+		if !slices.ContainsFunc(all, func(other map[Identifier]string) bool { return maps.Equal(values, other) }) {
+			all = append(all, values)
+		}
+	}
+
+	if len(all) == 0 {
+		err = fmt.Errorf("filename `%s` does not match the spec", filename)
+	}
+	return
+}
+
+// Captures matches a filename against the spec and returns the value captured
+// by each segment, keyed by its identifier. Identifiers of optional segments
+// which are not present in the filename are omitted. When an identifier
+// appears more than once, every occurrence after the first is reported under
+// the identifier suffixed by its position, such as `name_2`. A filename
+// violating the constraints of the spec is rejected, as with Extract.
+func (s Synta) Captures(filename string) (captures map[Identifier]string, err error) {
+	expr, err := s.buildCaptureRegexp()
+	if err != nil {
+		return
+	}
+
+	captures, values := expr.match(s.input(filename))
+	if captures == nil {
+		err = fmt.Errorf("filename `%s` does not match the spec", filename)
+		return
+	}
+	if err = s.checkCaptures(values); err != nil {
+		captures = nil
+		err = fmt.Errorf("filename `%s` does not match the spec: %v", filename, err)
+	}
+	return
+}
+
+// MatchPositions matches a filename against the spec and returns the start
+// and end byte offsets of the value of each identifier within the filename,
+// as with Extract the first occurrence of an identifier being reported.
+// Identifiers of optional segments which are not present in the filename are
+// omitted.
+func (s Synta) MatchPositions(filename string) (positions map[Identifier][2]int, err error) {
+	expr, err := s.buildCaptureRegexp()
+	if err != nil {
+		return
+	}
+
+	input := s.input(filename)
+	match := expr.expr.FindStringSubmatchIndex(input)
+	if match == nil {
+		err = fmt.Errorf("filename `%s` does not match the spec", filename)
+		return
+	}
+
+	positions = map[Identifier][2]int{}
+	values := map[Identifier][]string{}
+	for i, name := range expr.expr.SubexpNames() {
+		key, ok := expr.keys[name]
+		if !ok || match[2*i] < 0 {
+			continue
+		}
+		id := expr.identifiers[key]
+		if _, ok := positions[id]; !ok {
+			positions[id] = [2]int{match[2*i], match[2*i+1]}
+		}
+		values[id] = append(values[id], input[match[2*i]:match[2*i+1]])
+	}
+	if err = s.checkCaptures(values); err != nil {
+		positions = nil
+		err = fmt.Errorf("filename `%s` does not match the spec: %v", filename, err)
+	}
+	return
+}
+
+// captureRegexp is a regexp built by the spec with a capture group for each
+// segment, rather than for each identifier
+type captureRegexp struct {
+	expr *regexp.Regexp
+	// keys maps each group name to the key of its capture, such as `name_2`
+	keys map[string]Identifier
+	// identifiers maps each key to the identifier it was captured for
+	identifiers map[Identifier]Identifier
+}
+
+func (s Synta) buildCaptureRegexp() (c captureRegexp, err error) {
+	groups := newGroupNamer()
+	occurrences := map[Identifier]int{}
+	c.identifiers = map[Identifier]Identifier{}
+	c.expr, err = s.BuildRegexpWith(func(id Identifier, def Definition) string {
+		occurrences[id]++
+		key := id
+		if occurrences[id] > 1 {
+			key = Identifier(fmt.Sprintf("%s_%d", id, occurrences[id]))
+		}
+		c.identifiers[key] = id
+		return "(?P<" + groups.name(key) + ">" + def.Source() + ")"
+	})
+	c.keys = groups.names
+	return
+}
+
+// match returns the value captured by each segment, keyed as by Captures,
+// along with every value captured for each identifier. Both are nil if the
+// input does not match.
+func (c captureRegexp) match(input string) (captures map[Identifier]string, values map[Identifier][]string) {
+	match := c.expr.FindStringSubmatchIndex(input)
+	if match == nil {
+		return
+	}
+
+	captures = map[Identifier]string{}
+	values = map[Identifier][]string{}
+	for i, name := range c.expr.SubexpNames() {
+		key, ok := c.keys[name]
+		if !ok || match[2*i] < 0 {
+			continue
+		}
+		captures[key] = input[match[2*i]:match[2*i+1]]
+		values[c.identifiers[key]] = append(values[c.identifiers[key]], captures[key])
+	}
+	return
+}
+
+// input prepares a filename to be matched against the spec
+func (s Synta) input(filename string) string {
+	if s.LowercaseInput {
+		return strings.ToLower(filename)
+	}
+	return filename
+}
+
+// captureValues matches the filename against a regexp built by the spec and
+// returns the first value captured for each identifier, along with every
+// value captured for each identifier. Both are nil if the filename does not
+// match.
+func captureValues(expr *regexp.Regexp, names map[string]Identifier, filename string) (values map[Identifier]string, captures map[Identifier][]string) {
+	match := expr.FindStringSubmatchIndex(filename)
+	if match == nil {
+		return
+	}
+
+	values = map[Identifier]string{}
+	captures = map[Identifier][]string{}
+	for i, name := range expr.SubexpNames() {
+		id, ok := names[name]
+		if !ok || match[2*i] < 0 {
+			continue
+		}
+		value := filename[match[2*i]:match[2*i+1]]
+		if _, ok := values[id]; !ok {
+			values[id] = value
+		}
+		captures[id] = append(captures[id], value)
+	}
+	return
+}
+
+// ExtractWithDefaults works like Extract, but identifiers of optional
+// segments which are not present in the filename are given the value found in
+// defaults, if any. Every default must match the definition of its identifier.
+func (s Synta) ExtractWithDefaults(filename string, defaults map[Identifier]string) (values map[Identifier]string, err error) {
+	for id, value := range defaults {
+		def, ok := s.Definitions[id]
+		if !ok {
+			err = fmt.Errorf("default provided for `%s`, which has no definition", id)
+			return
+		}
+		matches, e := matchesDefinition(def, value)
+		if e != nil {
+			err = e
+			return
+		}
+		if !matches {
+			err = fmt.Errorf("default `%s` does not match the definition of `%s`", value, id)
+			return
+		}
+	}
+
+	values, err = s.Extract(filename)
+	if err != nil {
+		return
+	}
+	for id, value := range defaults {
+		if _, ok := values[id]; !ok {
+			values[id] = value
+		}
+	}
+	return
+}
+
+// matchesDefinition tells if the whole value matches the definition's regexp
+// and respects its length bounds
+func matchesDefinition(def Definition, value string) (matches bool, err error) {
+	expr, err := regexp.Compile("^(?:" + def.Source() + ")$")
+	if err != nil {
+		return
+	}
+	matches = expr.MatchString(value) && def.checkLength(value) == nil
+	return
+}
+
+// ExtractInto extracts the values of a filename and stores them in the struct
+// pointed to by dst, in the same fashion as encoding/json's Unmarshal. Fields
+// are bound to identifiers with a `synta:"identifier"` tag; adding the
+// `required` option (`synta:"identifier,required"`) makes the extraction fail
+// when the identifier is absent from the filename. Only string and integer
+// fields are supported.
+func (s Synta) ExtractInto(filename string, dst interface{}) (err error) {
+	ptr := reflect.ValueOf(dst)
+	if ptr.Kind() != reflect.Pointer || ptr.IsNil() || ptr.Elem().Kind() != reflect.Struct {
+		err = errors.New("destination must be a non-nil pointer to a struct")
+		return
+	}
+
+	values, err := s.Extract(filename)
+	if err != nil {
+		return
+	}
+
+	known := map[Identifier]bool{}
+	for _, id := range s.Filename.identifiers() {
+		known[id] = true
+	}
+
+	val := ptr.Elem()
+	for i := 0; i < val.NumField(); i++ {
+		field := val.Type().Field(i)
+		tag, ok := field.Tag.Lookup("synta")
+		if !ok || tag == "-" {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		id := Identifier(name)
+		if !known[id] {
+			err = fmt.Errorf("field %s references `%s`, which is not part of the filename", field.Name, id)
+			return
+		}
+
+		value, present := values[id]
+		if !present {
+			if opts == "required" {
+				err = fmt.Errorf("missing required field %s (`%s`)", field.Name, id)
+				return
+			}
+			continue
+		}
+
+		if err = setField(val.Field(i), value); err != nil {
+			err = fmt.Errorf("cannot set field %s: %v", field.Name, err)
+			return
+		}
+	}
+	return
+}
+
+func setField(field reflect.Value, value string) (err error) {
+	if !field.CanSet() {
+		return errors.New("field is not exported")
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, e := strconv.ParseInt(value, 10, field.Type().Bits())
+		if e != nil {
+			return e
+		}
+		field.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, e := strconv.ParseUint(value, 10, field.Type().Bits())
+		if e != nil {
+			return e
+		}
+		field.SetUint(n)
+	default:
+		err = fmt.Errorf("unsupported field type %s", field.Type())
+	}
+	return
+}
+
+func getAllIdentifiers(segments []Segment) (identifiers []Identifier) {
+	WalkSegments(segments, func(seg Segment) bool {
+		if seg.Kind == SegmentTypeIdentifier || seg.Kind == SegmentTypeBackreference {
+			identifiers = append(identifiers, *seg.Value)
+		}
+		return true
+	})
+	return
+}
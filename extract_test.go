@@ -0,0 +1,469 @@
+package synta
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const extractInput = `; the course name
+course = [a-z]+
+year = [0-9]{4}
+tag = [a-z]+
+ext = pdf|txt
+> course-year(-tag)?.ext`
+
+func TestExtract(t *testing.T) {
+	synta, err := ParseSynta(extractInput)
+	assert.Nil(t, err)
+
+	values, err := synta.Extract("analisi-2024-esame.pdf")
+	assert.Nil(t, err)
+	assert.Equal(t, map[Identifier]string{
+		"course": "analisi",
+		"year":   "2024",
+		"tag":    "esame",
+		"ext":    "pdf",
+	}, values)
+}
+
+func TestExtractWithMissingOptional(t *testing.T) {
+	synta, err := ParseSynta(extractInput)
+	assert.Nil(t, err)
+
+	values, err := synta.Extract("analisi-2024.txt")
+	assert.Nil(t, err)
+	assert.Equal(t, map[Identifier]string{
+		"course": "analisi",
+		"year":   "2024",
+		"ext":    "txt",
+	}, values)
+}
+
+func TestExtractWithNonMatchingFilename(t *testing.T) {
+	synta, err := ParseSynta(extractInput)
+	assert.Nil(t, err)
+
+	_, err = synta.Extract("analisi-24.pdf")
+	assert.NotNil(t, err)
+}
+
+func TestExtractInto(t *testing.T) {
+	synta, err := ParseSynta(extractInput)
+	assert.Nil(t, err)
+
+	var doc struct {
+		Course    string `synta:"course"`
+		Year      int    `synta:"year,required"`
+		Tag       string `synta:"tag"`
+		Extension string `synta:"ext"`
+		Ignored   string
+	}
+	err = synta.ExtractInto("analisi-2024-esame.pdf", &doc)
+	assert.Nil(t, err)
+	assert.Equal(t, "analisi", doc.Course)
+	assert.Equal(t, 2024, doc.Year)
+	assert.Equal(t, "esame", doc.Tag)
+	assert.Equal(t, "pdf", doc.Extension)
+	assert.Equal(t, "", doc.Ignored)
+}
+
+func TestExtractIntoWithMissingRequiredField(t *testing.T) {
+	synta, err := ParseSynta(extractInput)
+	assert.Nil(t, err)
+
+	var doc struct {
+		Tag string `synta:"tag,required"`
+	}
+	err = synta.ExtractInto("analisi-2024.pdf", &doc)
+	assert.NotNil(t, err)
+}
+
+func TestExtractIntoWithUnknownIdentifier(t *testing.T) {
+	synta, err := ParseSynta(extractInput)
+	assert.Nil(t, err)
+
+	var doc struct {
+		Author string `synta:"author"`
+	}
+	err = synta.ExtractInto("analisi-2024.pdf", &doc)
+	assert.NotNil(t, err)
+	assert.NotNil(t, synta.ExtractInto("analisi-2024.pdf", doc))
+}
+
+func TestExtractWithDefaults(t *testing.T) {
+	synta, err := ParseSynta(extractInput)
+	assert.Nil(t, err)
+
+	defaults := map[Identifier]string{"tag": "lezione"}
+	values, err := synta.ExtractWithDefaults("analisi-2024.pdf", defaults)
+	assert.Nil(t, err)
+	assert.Equal(t, map[Identifier]string{
+		"course": "analisi",
+		"year":   "2024",
+		"tag":    "lezione",
+		"ext":    "pdf",
+	}, values)
+
+	values, err = synta.ExtractWithDefaults("analisi-2024-esame.pdf", defaults)
+	assert.Nil(t, err)
+	assert.Equal(t, "esame", values["tag"])
+}
+
+func TestExtractWithInvalidDefaults(t *testing.T) {
+	synta, err := ParseSynta(extractInput)
+	assert.Nil(t, err)
+
+	_, err = synta.ExtractWithDefaults("analisi-2024.pdf", map[Identifier]string{"tag": "L1"})
+	assert.NotNil(t, err)
+
+	_, err = synta.ExtractWithDefaults("analisi-2024.pdf", map[Identifier]string{"author": "me"})
+	assert.NotNil(t, err)
+}
+
+func TestBuildRegexpWithSanitizedGroupNames(t *testing.T) {
+	first, second, third := Identifier("2nd"), Identifier("año"), Identifier("a_o")
+	synta := Synta{
+		Definitions: map[Identifier]Definition{
+			first:  {Regexp: regexp.MustCompile("[0-9]+")},
+			second: {Regexp: regexp.MustCompile("[a-z]+")},
+			third:  {Regexp: regexp.MustCompile("[a-z]+")},
+			"ext":  {Regexp: regexp.MustCompile("pdf")},
+		},
+		Filename: Filename{
+			Segments: []Segment{
+				{SegmentTypeIdentifier, &first, nil, nil},
+				{SegmentTypeIdentifier, &second, nil, nil},
+				{SegmentTypeIdentifier, &third, nil, nil},
+			},
+			Extension: "ext",
+		},
+	}
+
+	expr, err := synta.BuildRegexp()
+	assert.Nil(t, err)
+	assert.Equal(t, `^(?P<_2nd>[0-9]+)-(?P<a_o>[a-z]+)-(?P<a_o_2>[a-z]+)\.(?P<ext>pdf)$`, expr.String())
+
+	names, err := synta.GroupNames()
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]Identifier{"_2nd": first, "a_o": second, "a_o_2": third, "ext": "ext"}, names)
+
+	values, err := synta.Extract("2-abc-def.pdf")
+	assert.Nil(t, err)
+	assert.Equal(t, map[Identifier]string{first: "2", second: "abc", third: "def", "ext": "pdf"}, values)
+}
+
+func TestBuildRegexpWith(t *testing.T) {
+	synta, err := ParseSynta(extractInput)
+	assert.Nil(t, err)
+
+	expr, err := synta.BuildRegexpWith(func(id Identifier, def Definition) string {
+		return "(?P<" + strings.ToUpper(string(id)) + ">" + def.Source() + ")"
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, `^(?P<COURSE>[a-z]+)-(?P<YEAR>[0-9]{4})(?:-(?P<TAG>[a-z]+))?\.(?P<EXT>pdf|txt)$`, expr.String())
+	assert.Equal(t, []string{"", "COURSE", "YEAR", "TAG", "EXT"}, expr.SubexpNames())
+
+	def, err := synta.BuildRegexp()
+	assert.Nil(t, err)
+	same, err := synta.BuildRegexpWith(func(id Identifier, def Definition) string {
+		return "(?P<" + string(id) + ">" + def.Source() + ")"
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, def.String(), same.String())
+}
+
+func TestRegexp(t *testing.T) {
+	synta, err := ParseSynta(extractInput)
+	assert.Nil(t, err)
+
+	expr, err := synta.Regexp()
+	assert.Nil(t, err)
+	assert.Equal(t, `^(?:[a-z]+)-(?:[0-9]{4})(?:-(?:[a-z]+))?\.(?:pdf|txt)$`, expr.String())
+	assert.True(t, expr.MatchString("course-2024.pdf"))
+	assert.True(t, expr.MatchString("course-2024-tag.txt"))
+	assert.False(t, expr.MatchString("course-2024-.txt"))
+
+	synta.Definitions["tag"] = Definition{Pattern: "("}
+	_, err = synta.Regexp()
+	assert.NotNil(t, err)
+}
+
+func TestRegexpWithLeadingOptional(t *testing.T) {
+	synta := MustSynta(`tag = [a-z]+
+year = [0-9]{4}
+ext = pdf
+> (-tag)?-year.ext`)
+
+	expr, err := synta.Regexp()
+	assert.Nil(t, err)
+	assert.Equal(t, `^(?:(?:[a-z]+)-)?(?:[0-9]{4})\.(?:pdf)$`, expr.String())
+	assert.True(t, expr.MatchString("2024.pdf"))
+	assert.True(t, expr.MatchString("draft-2024.pdf"))
+	assert.False(t, expr.MatchString("-2024.pdf"))
+
+	filename, err := synta.Render(map[Identifier]string{"tag": "draft", "year": "2024", "ext": "pdf"})
+	assert.Nil(t, err)
+	assert.Equal(t, "draft-2024.pdf", filename)
+	assert.Nil(t, synta.SelfCheck())
+}
+
+func TestRegexpWithNestedOptionals(t *testing.T) {
+	synta := MustSynta(`lesson = lesson
+num = [0-9]{2}
+part = [a-z]
+ext = pdf
+> lesson(-num(-part)?)?.ext`)
+
+	expr, err := synta.Regexp()
+	assert.Nil(t, err)
+	assert.Equal(t, `^(?:lesson)(?:-(?:[0-9]{2})(?:-(?:[a-z]))?)?\.(?:pdf)$`, expr.String())
+	for _, filename := range []string{"lesson.pdf", "lesson-01.pdf", "lesson-01-a.pdf"} {
+		matches, err := synta.Match(filename)
+		assert.Nil(t, err)
+		assert.True(t, matches, filename)
+	}
+	// the inner separator only follows the value of the outer optional
+	for _, filename := range []string{"lesson--a.pdf", "lesson-a.pdf", "lesson-01-.pdf"} {
+		matches, err := synta.Match(filename)
+		assert.Nil(t, err)
+		assert.False(t, matches, filename)
+	}
+
+	synta = MustSynta(`lesson = lesson
+num = [0-9]{2}
+part = [a-z]
+ext = pdf
+> (-num(-part)?)?-lesson.ext`)
+	expr, err = synta.Regexp()
+	assert.Nil(t, err)
+	assert.Equal(t, `^(?:(?:[0-9]{2})(?:-(?:[a-z]))?-)?(?:lesson)\.(?:pdf)$`, expr.String())
+	assert.True(t, expr.MatchString("01-a-lesson.pdf"))
+	assert.False(t, expr.MatchString("01--lesson.pdf"))
+}
+
+func TestExtractAllWithAmbiguousFilename(t *testing.T) {
+	synta := MustSynta(`name = [a-z]+
+author = [a-z]+
+tag = [a-z]+
+ext = pdf
+> name(-author)?(-tag)?.ext`)
+
+	all, err := synta.ExtractAll("analisi-rossi.pdf")
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []map[Identifier]string{
+		{"name": "analisi", "author": "rossi", "ext": "pdf"},
+		{"name": "analisi", "tag": "rossi", "ext": "pdf"},
+	}, all)
+}
+
+func TestExtractAllWithUnambiguousFilename(t *testing.T) {
+	synta, err := ParseSynta(extractInput)
+	assert.Nil(t, err)
+
+	all, err := synta.ExtractAll("analisi-2024-esame.pdf")
+	assert.Nil(t, err)
+	assert.Equal(t, []map[Identifier]string{
+		{"course": "analisi", "year": "2024", "tag": "esame", "ext": "pdf"},
+	}, all)
+
+	_, err = synta.ExtractAll("analisi.pdf")
+	assert.NotNil(t, err)
+}
+
+func TestExtractWithLengthAnnotation(t *testing.T) {
+	synta := MustSynta(`code = [a-z]+ len(3,5)
+ext = pdf
+> code.ext`)
+
+	values, err := synta.Extract("abc.pdf")
+	assert.Nil(t, err)
+	assert.Equal(t, "abc", values["code"])
+
+	_, err = synta.Extract("ab.pdf")
+	assert.EqualError(t, err, "filename `ab.pdf` does not match the spec: invalid `code`: `ab` is 2 characters long, expected at least 3")
+	_, err = synta.Extract("abcdef.pdf")
+	assert.EqualError(t, err, "filename `abcdef.pdf` does not match the spec: invalid `code`: `abcdef` is 6 characters long, expected at most 5")
+}
+
+func TestExtractWithLowercaseInput(t *testing.T) {
+	input := `name = [a-z]+
+year = [0-9]{4}
+ext = pdf
+> name-year.ext`
+	synta, err := ParseSyntaWithOptions(input, Options{LowercaseInput: true})
+	assert.Nil(t, err)
+
+	values, err := synta.Extract("Report-2024.PDF")
+	assert.Nil(t, err)
+	assert.Equal(t, map[Identifier]string{"name": "report", "year": "2024", "ext": "pdf"}, values)
+
+	m, err := synta.Matcher()
+	assert.Nil(t, err)
+	assert.True(t, m.Match("Report-2024.PDF"))
+
+	synta, err = ParseSynta(input)
+	assert.Nil(t, err)
+	_, err = synta.Extract("Report-2024.PDF")
+	assert.NotNil(t, err)
+}
+
+func TestExtractWithInlineSegments(t *testing.T) {
+	synta := MustSynta(`name = [a-z]+
+ext = pdf
+> name-{[0-9]{4}}(-{v[0-9]+})?.ext`)
+
+	values, err := synta.Extract("report-2024-v2.pdf")
+	assert.Nil(t, err)
+	assert.Equal(t, map[Identifier]string{"name": "report", "ext": "pdf"}, values)
+
+	_, err = synta.Extract("report-2024.pdf")
+	assert.Nil(t, err)
+	_, err = synta.Extract("report-24.pdf")
+	assert.NotNil(t, err)
+
+	all, err := synta.ExtractAll("report-2024-v2.pdf")
+	assert.Nil(t, err)
+	assert.Equal(t, []map[Identifier]string{values}, all)
+}
+
+func TestCaptures(t *testing.T) {
+	synta, err := ParseSynta(extractInput)
+	assert.Nil(t, err)
+
+	captures, err := synta.Captures("course-2024.pdf")
+	assert.Nil(t, err)
+	assert.Equal(t, map[Identifier]string{"course": "course", "year": "2024", "ext": "pdf"}, captures)
+
+	captures, err = synta.Captures("course-2024-tag.pdf")
+	assert.Nil(t, err)
+	assert.Equal(t, map[Identifier]string{"course": "course", "year": "2024", "tag": "tag", "ext": "pdf"}, captures)
+
+	_, err = synta.Captures("course.pdf")
+	assert.NotNil(t, err)
+}
+
+func TestCapturesWithRepeatedIdentifiers(t *testing.T) {
+	synta := MustSynta(`name = [a-z]+
+year = [0-9]{4}
+ext = pdf
+> name-year-name(-year)?.ext`)
+
+	captures, err := synta.Captures("foo-2023-bar-2024.pdf")
+	assert.Nil(t, err)
+	assert.Equal(t, map[Identifier]string{
+		"name":   "foo",
+		"year":   "2023",
+		"name_2": "bar",
+		"year_2": "2024",
+		"ext":    "pdf",
+	}, captures)
+
+	captures, err = synta.Captures("foo-2023-bar.pdf")
+	assert.Nil(t, err)
+	assert.Equal(t, map[Identifier]string{"name": "foo", "year": "2023", "name_2": "bar", "ext": "pdf"}, captures)
+
+	synta = MustSynta(`name = [a-z]+
+ext = pdf
+> name-=name.ext`)
+	_, err = synta.Captures("foo-bar.pdf")
+	assert.NotNil(t, err)
+	captures, err = synta.Captures("foo-foo.pdf")
+	assert.Nil(t, err)
+	assert.Equal(t, map[Identifier]string{"name": "foo", "name_2": "foo", "ext": "pdf"}, captures)
+}
+
+func TestMatchPositions(t *testing.T) {
+	synta, err := ParseSynta(extractInput)
+	assert.Nil(t, err)
+
+	positions, err := synta.MatchPositions("algebra-2024-exam.pdf")
+	assert.Nil(t, err)
+	assert.Equal(t, map[Identifier][2]int{"course": {0, 7}, "year": {8, 12}, "tag": {13, 17}, "ext": {18, 21}}, positions)
+
+	// the optional tag is absent, so its -1 offsets are left out
+	positions, err = synta.MatchPositions("algebra-2024.pdf")
+	assert.Nil(t, err)
+	assert.Equal(t, map[Identifier][2]int{"course": {0, 7}, "year": {8, 12}, "ext": {13, 16}}, positions)
+
+	_, err = synta.MatchPositions("algebra.pdf")
+	assert.NotNil(t, err)
+
+	synta = MustSynta(`name = [a-z]+
+ext = pdf
+> name-=name.ext`)
+	positions, err = synta.MatchPositions("foo-foo.pdf")
+	assert.Nil(t, err)
+	assert.Equal(t, map[Identifier][2]int{"name": {0, 3}, "ext": {8, 11}}, positions)
+	_, err = synta.MatchPositions("foo-bar.pdf")
+	assert.NotNil(t, err)
+}
+
+func TestExtractWithAlternation(t *testing.T) {
+	synta := MustSynta(`lesson = lesson
+lab = lab
+number = [0-9]{2}
+ext = pdf
+> (lesson|lab)-number.ext`)
+
+	expr, err := synta.Regexp()
+	assert.Nil(t, err)
+	assert.Equal(t, `^(?:(?:lesson)|(?:lab))-(?:[0-9]{2})\.(?:pdf)$`, expr.String())
+
+	values, err := synta.Extract("lab-01.pdf")
+	assert.Nil(t, err)
+	assert.Equal(t, map[Identifier]string{"lab": "lab", "number": "01", "ext": "pdf"}, values)
+	_, err = synta.Extract("exam-01.pdf")
+	assert.NotNil(t, err)
+
+	synta = MustSynta(`name = [a-z]+
+lesson = lesson
+lab = lab
+ext = pdf
+> name(-(lesson|lab|{ex[0-9]}))?.ext`)
+	for filename, expected := range map[string]map[Identifier]string{
+		"algebra.pdf":        {"name": "algebra", "ext": "pdf"},
+		"algebra-lesson.pdf": {"name": "algebra", "lesson": "lesson", "ext": "pdf"},
+		"algebra-lab.pdf":    {"name": "algebra", "lab": "lab", "ext": "pdf"},
+		"algebra-ex1.pdf":    {"name": "algebra", "ext": "pdf"},
+	} {
+		values, err := synta.Extract(filename)
+		assert.Nil(t, err, filename)
+		assert.Equal(t, expected, values, filename)
+	}
+	assert.Nil(t, synta.SelfCheck())
+}
+
+func TestExtractWithRepeat(t *testing.T) {
+	synta := MustSynta(`name = [a-z]+
+tag = [a-z]+
+ext = pdf
+> name(-tag)+.ext`)
+
+	expr, err := synta.Regexp()
+	assert.Nil(t, err)
+	assert.Equal(t, `^(?:[a-z]+)(?:-(?:[a-z]+))+\.(?:pdf)$`, expr.String())
+
+	for _, filename := range []string{"report-urgent.pdf", "report-urgent-draft-final.pdf"} {
+		matches, err := synta.Match(filename)
+		assert.Nil(t, err)
+		assert.True(t, matches, filename)
+	}
+	matches, err := synta.Match("report.pdf")
+	assert.Nil(t, err)
+	assert.False(t, matches)
+
+	values, err := synta.Extract("report-urgent-draft-final.pdf")
+	assert.Nil(t, err)
+	assert.Equal(t, "report", values["name"])
+	assert.Contains(t, []string{"urgent", "final"}, values["tag"])
+	assert.Nil(t, synta.SelfCheck())
+
+	synta.MatchStrategy = PreferMostOptionals
+	_, err = synta.Extract("report-urgent-draft-final.pdf")
+	assert.Nil(t, err)
+	_, err = synta.Extract("report.pdf")
+	assert.NotNil(t, err)
+}
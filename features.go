@@ -0,0 +1,89 @@
+package synta
+
+import (
+	"sort"
+)
+
+// The grammar features a spec may use, as reported by RequiresFeatures
+const (
+	FeatureOptional          = "optional"
+	FeatureNestedOptional    = "nested-optional"
+	FeatureBackreference     = "backreference"
+	FeatureInline            = "inline"
+	FeatureAlternation       = "alternation"
+	FeatureRepeat            = "repeat"
+	FeatureRequireUnless     = "require-unless"
+	FeatureLiteral           = "literal"
+	FeatureNoExtension       = "no-extension"
+	FeatureLength            = "length"
+	FeatureKey               = "key"
+	FeatureSeparator         = "separator"
+	FeatureMultipleFilenames = "multiple-filenames"
+)
+
+// RequiresFeatures returns the grammar features used by the spec, sorted
+// alphabetically, so that tools can warn when a spec relies on features an
+// older consumer does not understand. A spec made only of definitions and
+// plain identifier segments requires no feature.
+func (s Synta) RequiresFeatures() (features []string) {
+	used := map[string]bool{}
+	for _, f := range s.filenames() {
+		collectSegmentFeatures(f.Segments, 0, used)
+		if len(f.Extensions) > 0 {
+			used[FeatureAlternation] = true
+		}
+		if !f.HasExtension() {
+			used[FeatureNoExtension] = true
+		}
+	}
+	if len(s.Alternatives) > 0 {
+		used[FeatureMultipleFilenames] = true
+	}
+	if _, ok := s.Key(); ok {
+		used[FeatureKey] = true
+	}
+	if s.Separator != "" {
+		used[FeatureSeparator] = true
+	}
+	for _, def := range s.Definitions {
+		if def.MinLen > 0 || def.MaxLen > 0 {
+			used[FeatureLength] = true
+		}
+	}
+	for _, c := range s.Constraints {
+		if c.Kind == ConstraintRequiredUnless {
+			used[FeatureRequireUnless] = true
+		}
+	}
+
+	for feature := range used {
+		features = append(features, feature)
+	}
+	sort.Strings(features)
+	return
+}
+
+func collectSegmentFeatures(segments []Segment, depth int, used map[string]bool) {
+	for _, segment := range segments {
+		switch segment.Kind {
+		case SegmentTypeOptional:
+			used[FeatureOptional] = true
+			if depth > 0 {
+				used[FeatureNestedOptional] = true
+			}
+			collectSegmentFeatures(segment.Subsegments, depth+1, used)
+		case SegmentTypeBackreference:
+			used[FeatureBackreference] = true
+		case SegmentTypeInline:
+			used[FeatureInline] = true
+		case SegmentTypeLiteral:
+			used[FeatureLiteral] = true
+		case SegmentTypeRepeat:
+			used[FeatureRepeat] = true
+			collectSegmentFeatures(segment.Subsegments, depth, used)
+		case SegmentTypeAlternation:
+			used[FeatureAlternation] = true
+			collectSegmentFeatures(segment.Subsegments, depth, used)
+		}
+	}
+}
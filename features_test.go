@@ -0,0 +1,80 @@
+package synta
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequiresFeaturesWithPlainSpec(t *testing.T) {
+	synta := MustSynta(`name = [a-z]+|[0-9]+
+ext = pdf
+> name-name.ext`)
+	assert.Empty(t, synta.RequiresFeatures())
+}
+
+func TestRequiresFeatures(t *testing.T) {
+	synta := MustSynta(`name = [a-z]+
+num = [0-9]+
+ext = pdf
+> name(-num)?.ext`)
+	assert.Equal(t, []string{FeatureOptional}, synta.RequiresFeatures())
+
+	synta = MustSynta(`name = [a-z]+
+num = [0-9]+
+ext = pdf
+! require num unless name
+> name(-num(-=name)?)?.ext`)
+	assert.Equal(t, []string{
+		FeatureBackreference,
+		FeatureNestedOptional,
+		FeatureOptional,
+		FeatureRequireUnless,
+	}, synta.RequiresFeatures())
+
+	synta = MustSynta(`name = [a-z]+
+ext = pdf
+> name-{[0-9]+}.ext`)
+	assert.Equal(t, []string{FeatureInline}, synta.RequiresFeatures())
+
+	synta = MustSynta(`name = [a-z]+
+ext = pdf
+> name-(name|{[0-9]+}).ext`)
+	assert.Equal(t, []string{FeatureAlternation, FeatureInline}, synta.RequiresFeatures())
+
+	synta = MustSynta(`num = [0-9]+
+ext = pdf
+> num\.num.ext`)
+	assert.Equal(t, []string{FeatureLiteral}, synta.RequiresFeatures())
+
+	synta = MustSynta(`name = [A-Z]+
+> name$`)
+	assert.Equal(t, []string{FeatureNoExtension}, synta.RequiresFeatures())
+
+	synta = MustSynta(`name = [a-z]+ len(2,3)
+ext = pdf
+> name.ext`)
+	assert.Equal(t, []string{FeatureLength}, synta.RequiresFeatures())
+
+	synta = MustSynta(`name = [a-z]+
+ext = pdf
+! key = name
+> name.ext`)
+	assert.Equal(t, []string{FeatureKey}, synta.RequiresFeatures())
+
+	synta = MustSynta(`name = [a-z]+
+ext = pdf
+! separator = _
+> name-name.ext`)
+	assert.Equal(t, []string{FeatureSeparator}, synta.RequiresFeatures())
+}
+
+func TestRequiresFeaturesWithAlternatives(t *testing.T) {
+	synta, err := ParseSyntaWithOptions(`name = [a-z]+
+year = [0-9]{4}
+ext = pdf
+> name.ext
+> name(-year)?$`, Options{MultipleFilenames: true})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{FeatureMultipleFilenames, FeatureNoExtension, FeatureOptional}, synta.RequiresFeatures())
+}
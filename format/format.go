@@ -2,6 +2,7 @@ package format
 
 import (
 	"sort"
+	"strings"
 
 	"github.com/cartabinaria/synta"
 )
@@ -21,30 +22,38 @@ func Format(syntaFile synta.Synta) (code string) {
 		for _, comment := range def.Comments {
 			code += "; " + comment + "\n"
 		}
-		code += string(id) + " = " + def.Regexp.String() + "\n\n"
+		code += string(id) + " = " + def.Expression() + "\n\n"
 	}
 
-	code += "> "
-	expr := formatSegments(syntaFile.Filename.Segments)
-	code += expr
-	code += "." + string(syntaFile.Filename.Extension) + "\n"
-
-	return
-}
-
-func formatSegments(segments []synta.Segment) (expr string) {
-	for i, segment := range segments {
-		switch segment.Kind {
-		case synta.SegmentTypeIdentifier:
-			expr += string(*segment.Value)
-		case synta.SegmentTypeOptional:
-			exp := formatSegments(segment.Subsegments)
-			expr += "(-" + exp + ")?"
+	directives := 0
+	for _, constraint := range syntaFile.Constraints {
+		if constraint.IsDirective() {
+			code += constraint.String() + "\n"
+			directives++
 		}
+	}
+	if key, ok := syntaFile.Key(); ok {
+		code += "! key = " + string(key) + "\n"
+		directives++
+	}
+	if syntaFile.Separator != "" {
+		code += "! separator = " + syntaFile.Separator + "\n"
+		directives++
+	}
+	if directives > 0 {
+		code += "\n"
+	}
 
-		if i != len(segments)-1 && segments[i+1].Kind != synta.SegmentTypeOptional {
-			expr += "-"
+	for _, filename := range append([]synta.Filename{syntaFile.Filename}, syntaFile.Alternatives...) {
+		for _, comment := range filename.Comments {
+			code += "; " + comment + "\n"
 		}
+		code += "> " + filename.String()
+		if comments := filename.ExtensionComments; len(comments) > 0 {
+			code += " ; " + strings.Join(comments, " ")
+		}
+		code += "\n"
 	}
+
 	return
 }
@@ -25,6 +25,14 @@ test = c|d
 	assert.Equal(t, formattedContent, formatted)
 }
 
+func TestFormatWithExtensionAlternation(t *testing.T) {
+	basicSynta, err := synta.ParseSynta("def = a|b\npdf = pdf\ntxt = txt\n> def.(pdf|txt)")
+	assert.Nil(t, err)
+
+	formatted := Format(basicSynta)
+	assert.Equal(t, "def = a|b\n\npdf = pdf\n\ntxt = txt\n\n> def.(pdf|txt)\n", formatted)
+}
+
 func TestFormatWithOptional(t *testing.T) {
 	basicContent := `def = a|b
 test = c|d
@@ -80,3 +88,164 @@ test = c|d
 `
 	assert.Equal(t, formattedContent, formatted)
 }
+
+func TestFormatWithDirectives(t *testing.T) {
+	basicContent := `! require test unless def
+! key = def
+def = a|b
+test = c|d
+> def(-test)?.test
+`
+	basicSynta, err := synta.ParseSynta(basicContent)
+	assert.Nil(t, err)
+
+	formatted := Format(basicSynta)
+	formattedContent := `def = a|b
+
+test = c|d
+
+! require test unless def
+! key = def
+
+> def(-test)?.test
+`
+	assert.Equal(t, formattedContent, formatted)
+}
+
+func TestFormatNormalizesCommentPrefixes(t *testing.T) {
+	basicContent := `# a test comment
+def = a|b
+> def.def
+`
+	basicSynta, err := synta.ParseSyntaWithOptions(basicContent, synta.Options{CommentPrefixes: []string{"#"}})
+	assert.Nil(t, err)
+
+	formattedContent := `; a test comment
+def = a|b
+
+> def.def
+`
+	assert.Equal(t, formattedContent, Format(basicSynta))
+}
+
+func TestFormatWithFilenameComments(t *testing.T) {
+	basicContent := `; the filename
+> def.def
+def = a|b
+`
+	basicSynta, err := synta.ParseSynta(basicContent)
+	assert.Nil(t, err)
+
+	formattedContent := `def = a|b
+
+; the filename
+> def.def
+`
+	assert.Equal(t, formattedContent, Format(basicSynta))
+}
+
+func TestFormatWithBackreference(t *testing.T) {
+	basicContent := `def = a|b
+test = c|d
+> def(-test)?-=def.test
+`
+	basicSynta, err := synta.ParseSynta(basicContent)
+	assert.Nil(t, err)
+
+	formattedContent := `def = a|b
+
+test = c|d
+
+> def(-test)?-=def.test
+`
+	assert.Equal(t, formattedContent, Format(basicSynta))
+}
+
+func TestFormatWithLengthAnnotation(t *testing.T) {
+	basicContent := `code = [a-z]+   len(3,5)
+> code.code
+`
+	basicSynta, err := synta.ParseSynta(basicContent)
+	assert.Nil(t, err)
+
+	formattedContent := `code = [a-z]+ len(3,5)
+
+> code.code
+`
+	assert.Equal(t, formattedContent, Format(basicSynta))
+}
+
+func TestFormatWithInlineSegments(t *testing.T) {
+	basicContent := `name = [a-z]+
+> name-{[0-9]{4}}(-{v[0-9]+})?.name
+`
+	basicSynta, err := synta.ParseSynta(basicContent)
+	assert.Nil(t, err)
+
+	formattedContent := `name = [a-z]+
+
+> name-{[0-9]{4}}(-{v[0-9]+})?.name
+`
+	assert.Equal(t, formattedContent, Format(basicSynta))
+}
+
+func TestFormatWithExtensionComment(t *testing.T) {
+	basicContent := `name = [a-z]+
+> name.name   ;   the name again
+`
+	basicSynta, err := synta.ParseSynta(basicContent)
+	assert.Nil(t, err)
+
+	formattedContent := `name = [a-z]+
+
+> name.name ; the name again
+`
+	assert.Equal(t, formattedContent, Format(basicSynta))
+}
+
+func TestFormatWithAlternation(t *testing.T) {
+	basicContent := `lesson = lesson
+lab = lab
+> (lesson|lab)(-(lab|{x+}))?.lab
+`
+	basicSynta, err := synta.ParseSynta(basicContent)
+	assert.Nil(t, err)
+
+	formattedContent := `lab = lab
+
+lesson = lesson
+
+> (lesson|lab)(-(lab|{x+}))?.lab
+`
+	assert.Equal(t, formattedContent, Format(basicSynta))
+}
+
+func TestFormatWithRepeat(t *testing.T) {
+	basicSynta, err := synta.ParseSynta("name = [a-z]+\n> name(-name)+(-name)?.name\n")
+	assert.Nil(t, err)
+	assert.Equal(t, "name = [a-z]+\n\n> name(-name)+(-name)?.name\n", Format(basicSynta))
+}
+
+func TestFormatWithLiteral(t *testing.T) {
+	basicSynta, err := synta.ParseSynta("num = [0-9]+\n> num\\.num(-num)?.num\n")
+	assert.Nil(t, err)
+	assert.Equal(t, "num = [0-9]+\n\n> num\\.num(-num)?.num\n", Format(basicSynta))
+}
+
+func TestFormatWithoutExtension(t *testing.T) {
+	basicSynta, err := synta.ParseSynta("name = [A-Z]+\n> name(-name)?$\n")
+	assert.Nil(t, err)
+	assert.Equal(t, "name = [A-Z]+\n\n> name(-name)?$\n", Format(basicSynta))
+}
+
+func TestFormatWithSeparator(t *testing.T) {
+	basicSynta, err := synta.ParseSynta("name = [a-z]+\n! separator = _\n> name-name.name\n")
+	assert.Nil(t, err)
+	assert.Equal(t, "name = [a-z]+\n\n! separator = _\n\n> name-name.name\n", Format(basicSynta))
+}
+
+func TestFormatWithAlternatives(t *testing.T) {
+	basicSynta, err := synta.ParseSyntaWithOptions("name = [a-z]+\nyear = [0-9]{4}\n> name-year.name\n; the undated ones\n> name.name\n", synta.Options{MultipleFilenames: true})
+	assert.Nil(t, err)
+	assert.Equal(t, "name = [a-z]+\n\nyear = [0-9]{4}\n\n> name-year.name\n; the undated ones\n> name.name\n", Format(basicSynta))
+}
@@ -0,0 +1,399 @@
+package synta
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"regexp/syntax"
+	"slices"
+)
+
+// generator synthesizes strings matching regexps. It handles literals,
+// character classes, alternations, groups and repetitions, while anchors and
+// word boundaries are ignored. Unbounded repetitions are repeated extra more
+// times than their minimum. The zero variation produces the simplest strings,
+// while other variations pick different characters, alternatives, repetitions
+// and optional segments. When rng is set, those choices are drawn from it
+// instead, and repeated segments are generated a random number of times.
+// When values is set, it holds the values generated for the identifiers
+// referenced by a backreference, so that every occurrence gets the same one.
+type generator struct {
+	extra     int
+	variation int
+	rng       *rand.Rand
+	values    map[Identifier]string
+}
+
+// maxRandomRepetitions bounds the repetitions of a repeated segment, and the
+// extra repetitions of an unbounded regexp, picked by a random generator
+const maxRandomRepetitions = 3
+
+// pick returns a random number in [0,n) when the generator is random, or the
+// given fallback otherwise
+func (g generator) pick(n, fallback int) int {
+	if g.rng == nil {
+		return fallback
+	}
+	return g.rng.Intn(n)
+}
+
+// generateFilename synthesizes a filename for the spec, including every
+// optional segment which can be generated
+func (g generator) generateFilename(s Synta) (filename string, err error) {
+	g.values = map[Identifier]string{}
+	filename, err = g.generateSegments(s, s.Filename.Segments)
+	if err != nil || !s.Filename.HasExtension() {
+		return
+	}
+	exts := s.Filename.extensions()
+	ext, err := g.generateDefinition(s, exts[g.pick(len(exts), 0)])
+	if err != nil {
+		return
+	}
+	filename += "." + ext
+	return
+}
+
+func (g generator) generateSegments(s Synta, segments []Segment) (expr string, err error) {
+	sep := s.separator()
+	for i, segment := range segments {
+		switch segment.Kind {
+		case SegmentTypeIdentifier, SegmentTypeBackreference:
+			value, e := g.generateIdentifier(s, *segment.Value)
+			if e != nil {
+				err = e
+				return
+			}
+			expr += value
+		case SegmentTypeInline:
+			value, e := g.generateInline(*segment.Inline)
+			if e != nil {
+				err = e
+				return
+			}
+			expr += value
+		case SegmentTypeLiteral:
+			expr += string(*segment.Value)
+		case SegmentTypeOptional:
+			leading := leadingGroup(segments, i)
+			if g.pick(2, g.variation>>i&1) == 0 {
+				// an optional segment which cannot be generated can be left out
+				if exp, e := g.generateSegments(s, segment.Subsegments); e == nil && leading {
+					expr += exp + sep
+				} else if e == nil {
+					expr += sep + exp
+				}
+			}
+			if leading {
+				continue
+			}
+		case SegmentTypeRepeat:
+			// unless random, a single repetition is generated, so that
+			// extracting the filename yields back the generated values
+			leading := leadingGroup(segments, i)
+			for n := 1 + g.pick(maxRandomRepetitions, 0); n > 0; n-- {
+				exp, e := g.generateSegments(s, segment.Subsegments)
+				if e != nil {
+					err = e
+					return
+				}
+				if leading {
+					expr += exp + sep
+				} else {
+					expr += sep + exp
+				}
+			}
+			if leading {
+				continue
+			}
+		case SegmentTypeAlternation:
+			// the first branch which can be generated is picked, starting
+			// from a different one for each variation
+			branches := segment.Subsegments
+			start := g.pick(len(branches), g.variation)
+			for j := range branches {
+				branch := branches[(j+start)%len(branches)]
+				value, e := g.generateSegments(s, []Segment{branch})
+				if err = e; e == nil {
+					expr += value
+					break
+				}
+			}
+			if err != nil {
+				return
+			}
+		}
+
+		if separated(segments, i) {
+			expr += sep
+		}
+	}
+	return
+}
+
+// generateIdentifier generates the value of an identifier segment, reusing
+// the value already generated for an identifier referenced by a
+// backreference, as all its occurrences must have the same value
+func (g generator) generateIdentifier(s Synta, id Identifier) (value string, err error) {
+	equal := slices.Contains(s.Constraints, Constraint{Kind: ConstraintEqual, Subject: id})
+	if value, ok := g.values[id]; ok && equal {
+		return value, nil
+	}
+	value, err = g.generateDefinition(s, id)
+	if err == nil && equal && g.values != nil {
+		g.values[id] = value
+	}
+	return
+}
+
+func (g generator) generateDefinition(s Synta, id Identifier) (value string, err error) {
+	def, ok := s.Definitions[id]
+	if !ok {
+		err = fmt.Errorf("missing definition for `%s`", id)
+		return
+	}
+
+	value, err = g.generateValue(def)
+	if err != nil {
+		err = fmt.Errorf("cannot generate a value for `%s`: %v", id, err)
+	}
+	return
+}
+
+func (g generator) generateInline(def Definition) (value string, err error) {
+	value, err = g.generateValue(def)
+	if err != nil {
+		err = fmt.Errorf("cannot generate a value for `{%s}`: %v", def.Source(), err)
+	}
+	return
+}
+
+func (g generator) generateValue(def Definition) (value string, err error) {
+	re, err := syntax.Parse(def.Source(), syntax.Perl)
+	if err != nil {
+		return
+	}
+	re = re.Simplify()
+	// values too short for the length bounds get more repetitions
+	base := g.pick(maxRandomRepetitions, g.variation%3)
+	for g.extra = base; g.extra <= base+def.MinLen; g.extra++ {
+		if value, err = g.generate(re); err != nil {
+			return
+		}
+		if err = def.checkLength(value); err == nil {
+			return
+		}
+	}
+	return
+}
+
+func (g generator) generate(re *syntax.Regexp) (value string, err error) {
+	switch re.Op {
+	case syntax.OpEmptyMatch, syntax.OpBeginLine, syntax.OpEndLine,
+		syntax.OpBeginText, syntax.OpEndText, syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		return "", nil
+	case syntax.OpLiteral:
+		return string(re.Rune), nil
+	case syntax.OpCharClass:
+		if len(re.Rune) == 0 {
+			return "", errors.New("empty character class")
+		}
+		return string(pickRune(re.Rune, g.pick(len(re.Rune)*26, g.variation))), nil
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return "x", nil
+	case syntax.OpCapture:
+		return g.generate(re.Sub[0])
+	case syntax.OpQuest:
+		if g.pick(2, 1) == 0 {
+			return g.generate(re.Sub[0])
+		}
+		return "", nil
+	case syntax.OpStar, syntax.OpPlus, syntax.OpRepeat:
+		count := re.Min
+		if re.Op == syntax.OpPlus {
+			count = 1
+		} else if re.Op == syntax.OpStar {
+			count = 0
+		}
+		if re.Op != syntax.OpRepeat || re.Max < 0 {
+			count += g.extra
+		} else {
+			count = min(count+g.extra, re.Max)
+		}
+		for i := 0; i < count; i++ {
+			sub, e := g.generate(re.Sub[0])
+			if e != nil {
+				return "", e
+			}
+			value += sub
+		}
+		return
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			v, e := g.generate(sub)
+			if e != nil {
+				return "", e
+			}
+			value += v
+		}
+		return
+	case syntax.OpAlternate:
+		start := g.pick(len(re.Sub), g.variation)
+		for i := range re.Sub {
+			if value, err = g.generate(re.Sub[(i+start)%len(re.Sub)]); err == nil {
+				return
+			}
+		}
+		return
+	case syntax.OpNoMatch:
+		return "", errors.New("the regexp matches nothing")
+	}
+	return "", fmt.Errorf("unsupported regexp construct %s", re)
+}
+
+// pickRune picks a rune from the ranges of a character class, preferring
+// lowercase letters and digits over other characters. Each variation picks a
+// different preferred rune, when the class contains more than one.
+func pickRune(ranges []rune, variation int) rune {
+	candidates := []rune{}
+	for _, preferred := range []rune("abcdefghijklmnopqrstuvwxyz0123456789") {
+		for i := 0; i < len(ranges); i += 2 {
+			if ranges[i] <= preferred && preferred <= ranges[i+1] {
+				candidates = append(candidates, preferred)
+				break
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		return ranges[0]
+	}
+	return candidates[variation%len(candidates)]
+}
+
+// IsSatisfiable tells whether the spec accepts at least one filename, by
+// generating a filename and checking that it matches the spec. An error is
+// returned only when the spec itself is invalid.
+func (s Synta) IsSatisfiable() (satisfiable bool, err error) {
+	if _, err = s.BuildRegexp(); err != nil {
+		return
+	}
+
+	filename, e := generator{}.generateFilename(s)
+	if e != nil {
+		return false, nil
+	}
+	_, e = s.Extract(filename)
+	return e == nil, nil
+}
+
+// Example returns a filename accepted by the spec, including every optional
+// segment which can be generated. Values are synthesized from the regexps of
+// the definitions: repetitions are kept to their minimum, character classes
+// prefer lowercase letters and digits, and optional parts of a regexp, such
+// as `x?`, are left out. Regexps matching nothing, such as empty character
+// classes, cannot be synthesized and make it fail, as do constraints the
+// generated filename happens to violate.
+func (s Synta) Example() (filename string, err error) {
+	filename, err = generator{}.generateFilename(s)
+	if err != nil {
+		return
+	}
+	matches, err := s.Match(filename)
+	if err == nil && !matches {
+		err = fmt.Errorf("generated filename `%s` does not satisfy the spec", filename)
+	}
+	return
+}
+
+// hasOwnIdentifier tells whether the optional segment holds an identifier or
+// a backreference outside of its nested optionals, or an alternation made
+// only of them, which Render relies on to decide whether it is present
+func hasOwnIdentifier(optional Segment) bool {
+	isIdentifier := func(seg Segment) bool {
+		return seg.Kind == SegmentTypeIdentifier || seg.Kind == SegmentTypeBackreference
+	}
+	for _, sub := range optional.Subsegments {
+		if isIdentifier(sub) || sub.Kind == SegmentTypeAlternation && !slices.ContainsFunc(sub.Subsegments, func(branch Segment) bool { return !isIdentifier(branch) }) {
+			return true
+		}
+	}
+	return false
+}
+
+// selfCheckVariations is the number of generated filenames SelfCheck tries
+const selfCheckVariations = 4
+
+// SelfCheck ensures that the spec round-trips: filenames generated from the
+// spec are captured and rendered back to the same filenames. It catches
+// specs which lose information on extraction. The text of an inline segment
+// matching more than one value is not captured, and Render cannot tell
+// whether an optional segment without identifiers of its own is present, so
+// specs with such segments, as `name-{[0-9]+}.ext` or `name(-{v1})?.ext`, are
+// only checked to match the generated filenames.
+func (s Synta) SelfCheck() (err error) {
+	if _, err = s.BuildRegexp(); err != nil {
+		return
+	}
+	renderable := true
+	WalkSegments(s.Filename.Segments, func(seg Segment) bool {
+		switch seg.Kind {
+		case SegmentTypeInline:
+			literal, ok := finiteStrings(seg.Inline.Source(), 2)
+			renderable = renderable && ok && len(literal) == 1
+		case SegmentTypeOptional:
+			renderable = renderable && hasOwnIdentifier(seg)
+		}
+		return true
+	})
+
+	for v := 0; v < selfCheckVariations; v++ {
+		filename, e := generator{variation: v}.generateFilename(s)
+		if e != nil {
+			return fmt.Errorf("cannot generate a filename: %v", e)
+		}
+		values, e := s.Captures(filename)
+		if e != nil {
+			return fmt.Errorf("generated filename `%s` does not match the spec: %v", filename, e)
+		}
+		if !renderable {
+			continue
+		}
+		rendered, e := s.Render(values)
+		if e != nil {
+			return fmt.Errorf("cannot render the values extracted from `%s`: %v", filename, e)
+		}
+		if rendered != filename {
+			return fmt.Errorf("filename `%s` is rendered back as `%s`", filename, rendered)
+		}
+	}
+	return
+}
+
+// maxGenerateAttempts bounds the filenames Generate draws before giving up,
+// as random ones may violate the constraints of the spec
+const maxGenerateAttempts = 16
+
+// Generate returns a random filename accepted by the spec, drawing from rng
+// whether each optional segment is present, how many times repeated segments
+// and regexp repetitions occur, and which characters and alternatives are
+// picked. Unlike Example, it aims for variety, while the same rng state
+// always yields the same filename, which suits property testing. An error is
+// returned when no filename satisfying the spec is drawn.
+func (s Synta) Generate(rng *rand.Rand) (filename string, err error) {
+	m, err := s.Matcher()
+	if err != nil {
+		return
+	}
+
+	g := generator{rng: rng}
+	for i := 0; i < maxGenerateAttempts; i++ {
+		if filename, err = g.generateFilename(s); err != nil {
+			return
+		}
+		if m.Match(filename) {
+			return
+		}
+	}
+	err = fmt.Errorf("generated filename `%s` does not satisfy the spec", filename)
+	return
+}
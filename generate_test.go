@@ -0,0 +1,169 @@
+package synta
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsSatisfiable(t *testing.T) {
+	synta := MustSynta(`name = [a-z]+
+date = [0-9]{8}
+tag = draft|final
+ext = pdf|txt
+> name-date(-tag)?.ext`)
+
+	satisfiable, err := synta.IsSatisfiable()
+	assert.Nil(t, err)
+	assert.True(t, satisfiable)
+
+	filename, err := generator{}.generateFilename(synta)
+	assert.Nil(t, err)
+	assert.Equal(t, "a-00000000-draft.pdf", filename)
+}
+
+func TestIsSatisfiableWithLengthAnnotation(t *testing.T) {
+	synta := MustSynta(`name = [a-z]+ len(3,)
+ext = pdf
+> name.ext`)
+
+	filename, err := generator{}.generateFilename(synta)
+	assert.Nil(t, err)
+	assert.Equal(t, "aaa.pdf", filename)
+
+	synta = MustSynta(`name = [a-z]{2} len(3,)
+ext = pdf
+> name.ext`)
+	satisfiable, err := synta.IsSatisfiable()
+	assert.Nil(t, err)
+	assert.False(t, satisfiable)
+}
+
+func TestIsSatisfiableWithEmptyClass(t *testing.T) {
+	synta := MustSynta(`name = [^\x00-\x{10FFFF}]
+ext = pdf
+> name.ext`)
+
+	satisfiable, err := synta.IsSatisfiable()
+	assert.Nil(t, err)
+	assert.False(t, satisfiable)
+}
+
+func TestIsSatisfiableWithUnsatisfiableOptional(t *testing.T) {
+	synta := MustSynta(`name = [a-z]+
+never = [^\x00-\x{10FFFF}]
+ext = pdf
+> name(-never)?.ext`)
+
+	satisfiable, err := synta.IsSatisfiable()
+	assert.Nil(t, err)
+	assert.True(t, satisfiable)
+}
+
+func TestSelfCheck(t *testing.T) {
+	synta, err := ParseSynta(extractInput)
+	assert.Nil(t, err)
+	assert.Nil(t, synta.SelfCheck())
+
+	// the identifier of the optional segment also appears outside of it
+	for _, input := range []string{
+		"name = [a-z]+\next = pdf\n> name(-name)?.ext",
+		"name = [a-z]+\nyear = [0-9]{4}\next = pdf\n> name(-year|-name)?.ext",
+		"name = [a-z]+\next = pdf\n> name(-=name)?.ext",
+	} {
+		assert.Nil(t, MustSynta(input).SelfCheck(), input)
+	}
+
+	// the text of the inline segment cannot be rendered back
+	synta = MustSynta(`name = [a-z]+
+ext = pdf
+> name-{[0-9]+}.ext`)
+	assert.Nil(t, synta.SelfCheck())
+	assert.Nil(t, MustSynta("name = [a-z]+\next = pdf\n> name-{v1}.ext").SelfCheck())
+
+	// the presence of an optional segment without identifiers is not captured
+	for _, input := range []string{
+		"name = [a-z]+\next = pdf\n> name(-{v1})?.ext",
+		"name = [a-z]+\nyear = [0-9]{4}\next = pdf\n> name(-{v1}(-year)?)?.ext",
+		"name = [a-z]+\next = pdf\n> name(-{v1}|-name)?.ext",
+	} {
+		assert.Nil(t, MustSynta(input).SelfCheck(), input)
+	}
+}
+
+func TestExample(t *testing.T) {
+	for _, input := range []string{
+		`name = [a-z]+
+ext = pdf
+> name.ext`,
+		`course = [a-z]+(-[a-z]+)*
+year = [0-9]{4}
+tag = v[0-9]+|draft
+ext = pdf|txt
+> course-year(-tag)?.ext`,
+		`name = \w{3,}
+code = [A-Z]{2}[0-9]*
+ext = md
+> name(-code(-=name)?)?-{x+}.ext`,
+	} {
+		synta := MustSynta(input)
+		example, err := synta.Example()
+		assert.Nil(t, err, input)
+		matches, err := synta.Match(example)
+		assert.Nil(t, err)
+		assert.True(t, matches, example)
+	}
+
+	example, err := MustSynta(`course = [a-z]+
+year = [0-9]{4}
+tag = v[0-9]+
+ext = pdf
+> course-year(-tag)?.ext`).Example()
+	assert.Nil(t, err)
+	assert.Equal(t, "a-0000-v0.pdf", example)
+
+	_, err = MustSynta(`name = [^\x00-\x{10FFFF}]
+ext = pdf
+> name.ext`).Example()
+	assert.NotNil(t, err)
+}
+
+func TestGenerate(t *testing.T) {
+	synta := MustSynta(`course = [a-z]+
+year = [0-9]{4}
+tag = draft|final
+note = [a-z]+(?:_[0-9]+)?
+ext = pdf|txt
+> course-year(-tag)?(-note)+.ext`)
+
+	seen := map[string]bool{}
+	for seed := int64(0); seed < 200; seed++ {
+		filename, err := synta.Generate(rand.New(rand.NewSource(seed)))
+		assert.Nil(t, err)
+		matches, err := synta.Match(filename)
+		assert.Nil(t, err)
+		assert.True(t, matches, filename)
+		seen[filename] = true
+
+		again, err := synta.Generate(rand.New(rand.NewSource(seed)))
+		assert.Nil(t, err)
+		assert.Equal(t, filename, again)
+	}
+	assert.Greater(t, len(seen), 100)
+
+	// backreferences repeat the value generated for their identifier
+	for _, spec := range []string{"name-=name.ext", "name(-tag)?-=name.ext", "=name-tag-name.ext", "name(-=name)+.ext"} {
+		synta := MustSynta("name = [a-z]+\ntag = [0-9]+\next = pdf\n> " + spec)
+		for seed := int64(0); seed < 50; seed++ {
+			filename, err := synta.Generate(rand.New(rand.NewSource(seed)))
+			assert.Nil(t, err, spec)
+			matches, err := synta.Match(filename)
+			assert.Nil(t, err)
+			assert.True(t, matches, filename)
+		}
+	}
+
+	_, err := MustSynta("name = [a-z]{2} len(3,)\next = pdf\n> name.ext").Generate(rand.New(rand.NewSource(0)))
+	assert.NotNil(t, err)
+}
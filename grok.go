@@ -0,0 +1,61 @@
+package synta
+
+import (
+	"regexp"
+	"regexp/syntax"
+)
+
+// grokBuiltins maps the Grok built-in patterns to the regexps they are
+// equivalent to, as far as filenames are concerned
+var grokBuiltins = map[string]string{
+	"NONNEGINT":  `[0-9]+`,
+	"INT":        `[+-]?[0-9]+`,
+	"WORD":       `\w+`,
+	"NOTSPACE":   `\S+`,
+	"DATA":       `.*?`,
+	"GREEDYDATA": `.*`,
+	"UUID":       `[A-Fa-f0-9]{8}-[A-Fa-f0-9]{4}-[A-Fa-f0-9]{4}-[A-Fa-f0-9]{4}-[A-Fa-f0-9]{12}`,
+	"USERNAME":   `[a-zA-Z0-9._-]+`,
+	"POSINT":     `[1-9][0-9]*`,
+	"MONTHNUM2":  `0[1-9]|1[0-2]`,
+}
+
+// GrokPattern exports the filename as a Grok pattern, for tools parsing
+// filenames found in logs. Each identifier becomes a `%{PATTERN:name}`
+// placeholder when its definition is equivalent to a Grok built-in pattern,
+// and a `(?<name>regexp)` named capture otherwise.
+func (s Synta) GrokPattern() (pattern string, err error) {
+	builtins := map[string]string{}
+	for name, expr := range grokBuiltins {
+		builtins[normalizeRegexp(expr)] = name
+	}
+
+	placeholder := func(id Identifier, def Definition) string {
+		if name, ok := builtins[normalizeRegexp(def.Source())]; ok {
+			return "%{" + name + ":" + string(id) + "}"
+		}
+		return "(?<" + string(id) + ">" + def.Source() + ")"
+	}
+
+	pattern, err = buildSegments(s.Definitions, s.Filename.Segments, regexp.QuoteMeta(s.separator()), placeholder)
+	if err != nil {
+		return
+	}
+	ext, err := s.extensionPattern(placeholder)
+	if err != nil {
+		return
+	}
+	pattern += ext
+	return
+}
+
+// normalizeRegexp rewrites a regexp in a canonical form, so that equivalent
+// spellings such as `\d+` and `[0-9]+` compare equal. Invalid regexps are
+// returned unchanged.
+func normalizeRegexp(expr string) string {
+	re, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return expr
+	}
+	return re.Simplify().String()
+}
@@ -0,0 +1,22 @@
+package synta
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGrokPattern(t *testing.T) {
+	synta := MustSynta(`name = [a-z]+
+num = \d+
+id = [0-9]+
+ext = pdf|txt
+> name-num(-id)?.ext`)
+
+	pattern, err := synta.GrokPattern()
+	assert.Nil(t, err)
+	assert.Equal(t, `(?<name>[a-z]+)-%{NONNEGINT:num}(?:-%{NONNEGINT:id})?\.(?<ext>pdf|txt)`, pattern)
+
+	_, err = Synta{Filename: synta.Filename}.GrokPattern()
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,45 @@
+package synta
+
+import (
+	"fmt"
+)
+
+// highlightColors are the ANSI foreground colors cycled through by Highlight
+var highlightColors = []string{"\x1b[31m", "\x1b[32m", "\x1b[33m", "\x1b[34m", "\x1b[35m", "\x1b[36m"}
+
+const highlightReset = "\x1b[0m"
+
+// Highlight returns the filename with each matched segment, extension
+// included, wrapped in a different ANSI color, to visually show how it is
+// split by the spec. When colors is false the filename is returned as is,
+// which is handy when the output is not a terminal.
+func (s Synta) Highlight(filename string, colors bool) (highlighted string, err error) {
+	expr, names, err := s.buildRegexp()
+	if err != nil {
+		return
+	}
+
+	match := expr.FindStringSubmatchIndex(filename)
+	if match == nil {
+		err = fmt.Errorf("filename `%s` does not match the spec", filename)
+		return
+	}
+	if !colors {
+		return filename, nil
+	}
+
+	last, segment := 0, 0
+	for i, name := range expr.SubexpNames() {
+		start, end := match[2*i], match[2*i+1]
+		if _, ok := names[name]; !ok || start < 0 {
+			continue
+		}
+
+		color := highlightColors[segment%len(highlightColors)]
+		highlighted += filename[last:start] + color + filename[start:end] + highlightReset
+		last = end
+		segment++
+	}
+	highlighted += filename[last:]
+	return
+}
@@ -0,0 +1,35 @@
+package synta
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var ansiCode = regexp.MustCompile("\x1b\\[[0-9]+m")
+
+func TestHighlight(t *testing.T) {
+	synta, err := ParseSynta(extractInput)
+	assert.Nil(t, err)
+
+	highlighted, err := synta.Highlight("analisi-2024-esame.pdf", true)
+	assert.Nil(t, err)
+	assert.Equal(t, "|analisi|-|2024|-|esame|.|pdf|", ansiCode.ReplaceAllString(highlighted, "|"))
+
+	highlighted, err = synta.Highlight("analisi-2024.pdf", true)
+	assert.Nil(t, err)
+	assert.Equal(t, "|analisi|-|2024|.|pdf|", ansiCode.ReplaceAllString(highlighted, "|"))
+}
+
+func TestHighlightWithoutColors(t *testing.T) {
+	synta, err := ParseSynta(extractInput)
+	assert.Nil(t, err)
+
+	highlighted, err := synta.Highlight("analisi-2024-esame.pdf", false)
+	assert.Nil(t, err)
+	assert.Equal(t, "analisi-2024-esame.pdf", highlighted)
+
+	_, err = synta.Highlight("analisi.pdf", false)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,19 @@
+package synta
+
+// ImpactOf returns the filenames of the corpus which currently match the spec
+// and rely on the definition of id to do so, that is the ones which could
+// stop matching if the definition were removed or made stricter. Filenames
+// which do not match, or where id belongs to an absent optional segment, are
+// not affected. The corpus order is preserved.
+func (s Synta) ImpactOf(id Identifier, corpus []string) (wouldFailNow []string) {
+	for _, filename := range corpus {
+		values, err := s.Extract(filename)
+		if err != nil {
+			continue
+		}
+		if _, ok := values[id]; ok {
+			wouldFailNow = append(wouldFailNow, filename)
+		}
+	}
+	return
+}
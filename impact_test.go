@@ -0,0 +1,22 @@
+package synta
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestImpactOf(t *testing.T) {
+	synta, err := ParseSynta(extractInput)
+	assert.Nil(t, err)
+
+	corpus := []string{
+		"analisi-2024-esame.pdf",
+		"analisi-2024.pdf",
+		"fisica-2023-appunti.txt",
+		"not-matching.pdf",
+	}
+	assert.Equal(t, []string{"analisi-2024-esame.pdf", "fisica-2023-appunti.txt"}, synta.ImpactOf("tag", corpus))
+	assert.Equal(t, corpus[:3], synta.ImpactOf("year", corpus))
+	assert.Empty(t, synta.ImpactOf("unknown", corpus))
+}
@@ -2,29 +2,40 @@ package json
 
 import (
 	"encoding/json"
+	"fmt"
+	"strings"
 
 	"github.com/cartabinaria/synta"
 )
 
+// Convert translates the spec into the json format, which only holds the
+// definitions and the filename: the parts listed by unrepresented are left
+// out, and ToJson rejects the specs using them. The json format predates
+// synta.Synta's MarshalJSON, which encodes every part of the spec in a
+// different layout, and the two cannot be read as one another.
 func Convert(syn synta.Synta) (s Synta) {
 	s.Definitions = map[string]Definition{}
 	for id, def := range syn.Definitions {
 		if len(def.Comments) == 0 {
 			s.Definitions[string(id)] = Definition{
-				Comments: []string{}, Regexp: def.Regexp.String()}
+				Comments: []string{}, Regexp: def.Source()}
 		} else {
 			s.Definitions[string(id)] = Definition{
-				Comments: def.Comments, Regexp: def.Regexp.String()}
+				Comments: def.Comments, Regexp: def.Source()}
 		}
 	}
 	for _, e := range syn.Filename.Segments {
 		seg := Segment{}
 		switch e.Kind {
-		case synta.SegmentTypeIdentifier:
+		case synta.SegmentTypeIdentifier, synta.SegmentTypeBackreference, synta.SegmentTypeLiteral:
 			seg.Value = string(*e.Value)
 			seg.Kind = uint(e.Kind)
 			seg.Subsegments = []Segment{}
-		case synta.SegmentTypeOptional:
+		case synta.SegmentTypeInline:
+			seg.Value = e.Inline.Source()
+			seg.Kind = uint(e.Kind)
+			seg.Subsegments = []Segment{}
+		case synta.SegmentTypeOptional, synta.SegmentTypeAlternation, synta.SegmentTypeRepeat:
 			seg.Value = ""
 			seg.Kind = uint(e.Kind)
 			seg.Subsegments = getSubSegments(e)
@@ -32,23 +43,63 @@ func Convert(syn synta.Synta) (s Synta) {
 		s.Filename.Segments = append(s.Filename.Segments, seg)
 	}
 	s.Filename.Extension = string(syn.Filename.Extension)
+	for _, ext := range syn.Filename.Extensions {
+		s.Filename.Extensions = append(s.Filename.Extensions, string(ext))
+	}
 	return
 }
 
+// ToJson encodes the spec in the json format. It fails when the spec uses
+// something the format cannot hold, rather than silently dropping it.
 func ToJson(synta synta.Synta) (buf []byte, err error) {
+	if parts := unrepresented(synta); len(parts) > 0 {
+		err = fmt.Errorf("the json format cannot represent %s", strings.Join(parts, ", "))
+		return
+	}
 	buf, err = json.Marshal(Convert(synta))
 	return
 }
 
+// unrepresented lists the parts of the spec which the json format cannot
+// hold
+func unrepresented(syn synta.Synta) (parts []string) {
+	for _, def := range syn.Definitions {
+		if def.MinLen > 0 || def.MaxLen > 0 {
+			parts = append(parts, "length bounds")
+			break
+		}
+	}
+	if len(syn.Alternatives) > 0 {
+		parts = append(parts, "alternative filenames")
+	}
+	for _, c := range syn.Constraints {
+		if c.IsDirective() {
+			parts = append(parts, "require directives")
+			break
+		}
+	}
+	if _, ok := syn.Key(); ok {
+		parts = append(parts, "the key directive")
+	}
+	if syn.Separator != "" {
+		parts = append(parts, "the separator directive")
+	}
+	return
+}
+
 func getSubSegments(segment synta.Segment) (subSegments []Segment) {
 	for _, e := range segment.Subsegments {
 		seg := Segment{}
 		switch e.Kind {
-		case synta.SegmentTypeIdentifier:
+		case synta.SegmentTypeIdentifier, synta.SegmentTypeBackreference, synta.SegmentTypeLiteral:
 			seg.Value = string(*e.Value)
 			seg.Kind = uint(e.Kind)
 			seg.Subsegments = []Segment{}
-		case synta.SegmentTypeOptional:
+		case synta.SegmentTypeInline:
+			seg.Value = e.Inline.Source()
+			seg.Kind = uint(e.Kind)
+			seg.Subsegments = []Segment{}
+		case synta.SegmentTypeOptional, synta.SegmentTypeAlternation, synta.SegmentTypeRepeat:
 			seg.Value = ""
 			seg.Kind = uint(e.Kind)
 			seg.Subsegments = getSubSegments(e)
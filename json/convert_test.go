@@ -79,3 +79,27 @@ etest = a|b
 	assert.Nil(t, err)
 	checkSynta(t, syn, expectedConvert)
 }
+
+func TestToJsonWithUnrepresentedParts(t *testing.T) {
+	syn, err := synta.ParseSynta("test = a|b\n> test-test.test")
+	assert.Nil(t, err)
+	_, err = ToJson(syn)
+	assert.Nil(t, err)
+
+	syn, err = synta.ParseSynta(`id = [0-9]+ len(,3)
+tag = [a-z]+
+author = [a-z]+
+ext = pdf
+! require tag unless author
+! key = id
+! separator = _
+> id(-tag)?(-author)?.ext`)
+	assert.Nil(t, err)
+	_, err = ToJson(syn)
+	assert.EqualError(t, err, "the json format cannot represent length bounds, require directives, the key directive, the separator directive")
+
+	syn, err = synta.ParseSyntaWithOptions("test = a|b\n> test.test\n> test-test.test", synta.Options{MultipleFilenames: true})
+	assert.Nil(t, err)
+	_, err = ToJson(syn)
+	assert.EqualError(t, err, "the json format cannot represent alternative filenames")
+}
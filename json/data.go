@@ -19,8 +19,9 @@ type Segment struct {
 // Filename represents the flename defintion, made up
 // of a series of segments and a file extension
 type Filename struct {
-	Segments  []Segment `json:"segments"`
-	Extension string    `json:"extension"`
+	Segments   []Segment `json:"segments"`
+	Extension  string    `json:"extension"`
+	Extensions []string  `json:"extensions,omitempty"`
 }
 
 // Synta represents the contents of a Synta file
@@ -0,0 +1,301 @@
+package synta
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// TokenType identifies the kind of a Token
+type TokenType uint
+
+const (
+	// TokenEOF is returned once the whole input has been consumed
+	TokenEOF TokenType = iota
+	// TokenError holds a character the lexer could not recognize, and is
+	// only produced when the Lexer recovers from errors
+	TokenError
+	// TokenComment is a comment line, its value excludes the prefix
+	TokenComment
+	// TokenDirective is a directive line, its value excludes the "!"
+	TokenDirective
+	// TokenIdentifier is the identifier of a definition or of a segment
+	TokenIdentifier
+	// TokenAssign is the " = " separating a definition from its regexp
+	TokenAssign
+	// TokenPattern is the regexp of a definition
+	TokenPattern
+	// TokenFilename is the ">" introducing the filename
+	TokenFilename
+	// TokenDash separates the segments of the filename
+	TokenDash
+	// TokenOpen opens an optional segment
+	TokenOpen
+	// TokenClose closes an optional segment
+	TokenClose
+	// TokenQuestion follows the closing parenthesis of an optional segment
+	TokenQuestion
+	// TokenDot separates the extension from the rest of the filename
+	TokenDot
+	// TokenBackreference is the "=" introducing a backreference segment
+	TokenBackreference
+	// TokenInline is an inline segment, its value excludes the braces
+	TokenInline
+	// TokenPipe separates the branches of an alternation
+	TokenPipe
+	// TokenPlus follows the closing parenthesis of a repeated segment
+	TokenPlus
+	// TokenLiteral is a run of escaped separators, its value excludes the
+	// backslashes
+	TokenLiteral
+	// TokenEnd is the "$" ending a filename without extension
+	TokenEnd
+)
+
+var tokenNames = []string{
+	TokenEOF:           "EOF",
+	TokenError:         "Error",
+	TokenComment:       "Comment",
+	TokenDirective:     "Directive",
+	TokenIdentifier:    "Identifier",
+	TokenAssign:        "Assign",
+	TokenPattern:       "Pattern",
+	TokenFilename:      "Filename",
+	TokenDash:          "Dash",
+	TokenOpen:          "Open",
+	TokenClose:         "Close",
+	TokenQuestion:      "Question",
+	TokenDot:           "Dot",
+	TokenBackreference: "Backreference",
+	TokenInline:        "Inline",
+	TokenPipe:          "Pipe",
+	TokenPlus:          "Plus",
+	TokenLiteral:       "Literal",
+	TokenEnd:           "End",
+}
+
+func (t TokenType) String() string {
+	if int(t) < len(tokenNames) {
+		return tokenNames[t]
+	}
+	return fmt.Sprintf("TokenType(%d)", uint(t))
+}
+
+// A Token is a lexical element of a Synta file. Line is 1-based, while Pos
+// is the 0-based byte offset of the token within its line.
+type Token struct {
+	Type  TokenType
+	Value string
+	Line  int
+	Pos   int
+}
+
+// A Lexer splits the contents of a Synta file into tokens. By default it
+// stops at the first character it cannot recognize; with Recover, such
+// characters are returned as TokenError tokens and lexing continues, which
+// suits tools highlighting files as they are edited. The parser reads the
+// definitions and filenames through it as well.
+type Lexer struct {
+	Recover bool
+
+	opts    Options
+	lines   []string
+	line    int
+	pending []Token
+	err     error
+	// continuation locates the trailing `\` of a pattern continued on the
+	// next line, if any
+	continuation *Token
+}
+
+// NewLexer returns a lexer for the given contents, recognizing comments
+// according to the options. Both "\r\n" and "\r" are treated as line breaks.
+func NewLexer(contents string, opts Options) *Lexer {
+	return &Lexer{opts: opts, lines: splitLines(contents)}
+}
+
+// splitLines splits the contents into lines, normalizing the Windows and
+// classic Mac line endings to "\n" beforehand
+func splitLines(contents string) []string {
+	contents = strings.ReplaceAll(contents, "\r\n", "\n")
+	contents = strings.ReplaceAll(contents, "\r", "\n")
+	return strings.Split(contents, "\n")
+}
+
+// NextToken returns the next token of the input, or TokenEOF once the input
+// is exhausted. Once an error is returned, every following call returns it.
+func (l *Lexer) NextToken() (tok Token, err error) {
+	for len(l.pending) == 0 && l.err == nil {
+		if l.line >= len(l.lines) {
+			if l.continuation == nil || l.Recover {
+				return Token{Type: TokenEOF, Line: len(l.lines)}, nil
+			}
+			l.err = SyntaError{l.continuation.Line, l.continuation.Pos, "dangling `\\` continuation at the end of the file", TokenPattern}
+			break
+		}
+		l.line++
+		l.pending, l.err = l.lexLine(l.lines[l.line-1])
+	}
+	if len(l.pending) == 0 {
+		return tok, l.err
+	}
+
+	tok, l.pending = l.pending[0], l.pending[1:]
+	return
+}
+
+// Tokenize reads a Synta file and splits it into tokens with the default
+// options, up to and including the TokenEOF one. On error, the tokens read
+// until then are returned along with it.
+func Tokenize(r io.Reader) (tokens []Token, err error) {
+	contents, err := io.ReadAll(r)
+	if err != nil {
+		return
+	}
+
+	l := NewLexer(string(contents), Options{})
+	for {
+		tok, e := l.NextToken()
+		if e != nil {
+			return tokens, e
+		}
+		tokens = append(tokens, tok)
+		if tok.Type == TokenEOF {
+			return
+		}
+	}
+}
+
+func (l *Lexer) lexLine(raw string) (tokens []Token, err error) {
+	line := strings.TrimRight(raw, " \t")
+	start := len(line) - len(strings.TrimLeft(line, " \t"))
+	line = line[start:]
+	if line == "" {
+		return
+	}
+
+	if l.continuation != nil {
+		return l.lexPattern(line, start), nil
+	}
+	if comment, ok := l.opts.comment(line); ok {
+		return []Token{{TokenComment, comment, l.line, start}}, nil
+	}
+	switch line[0] {
+	case '!':
+		return []Token{{TokenDirective, strings.TrimSpace(line[1:]), l.line, start}}, nil
+	case '>':
+		tokens = append(tokens, Token{TokenFilename, ">", l.line, start})
+		declaration, comment, at := splitTrailingComment(line, l.opts)
+		filename := strings.TrimLeft(declaration[1:], " ")
+		rest, e := l.lexFilename(filename, start+len(declaration)-len(filename))
+		tokens = append(tokens, rest...)
+		if e == nil && at >= 0 {
+			tokens = append(tokens, Token{TokenComment, comment, l.line, start + at})
+		}
+		return tokens, e
+	}
+
+	id, pattern, found := strings.Cut(line, " = ")
+	if !found {
+		return l.fail(tokens, Token{TokenError, line, l.line, start + len(line)}, fmt.Errorf("expected ` = ` at line %d, column %d", l.line, start+len(line)))
+	}
+	idToken := Token{TokenIdentifier, id, l.line, start}
+	if i := invalidIdentifierChar(id); i >= 0 {
+		if !l.Recover {
+			return tokens, SyntaError{l.line, start + i, fmt.Sprintf("unexpected character `%c` in identifier at line %d, column %d", []rune(id[i:])[0], l.line, start+i), TokenIdentifier}
+		}
+		idToken.Type = TokenError
+	}
+	tokens = []Token{idToken, {TokenAssign, "=", l.line, start + len(id) + 1}}
+	return append(tokens, l.lexPattern(pattern, start+len(id)+3)...), nil
+}
+
+// lexPattern splits the pattern, or the part of it, found at the given offset
+// of its line into tokens, followed by the comment trailing it, if any. A
+// trailing `\` continuing the pattern on the next line is left out of them.
+func (l *Lexer) lexPattern(pattern string, offset int) (tokens []Token) {
+	l.continuation = nil
+	if endsWithContinuation(pattern) {
+		pattern = pattern[:len(pattern)-1]
+		l.continuation = &Token{TokenPattern, `\`, l.line, offset + len(pattern)}
+	}
+	expr, comment, at := splitPatternComment(pattern, l.opts)
+	tokens = append(tokens, Token{TokenPattern, expr, l.line, offset})
+	if at >= 0 {
+		tokens = append(tokens, Token{TokenComment, comment, l.line, offset + at})
+	}
+	return
+}
+
+// invalidIdentifierChar returns the offset of the first character of the
+// identifier which is not a letter, or -1 if there is none
+func invalidIdentifierChar(id string) int {
+	return strings.IndexFunc(id, func(r rune) bool { return !isLetter(r) })
+}
+
+// lexFilename splits the filename declaration, which starts at the given
+// offset of its line, into tokens
+func (l *Lexer) lexFilename(filename string, offset int) (tokens []Token, err error) {
+	single := map[rune]TokenType{
+		'-': TokenDash,
+		'(': TokenOpen,
+		')': TokenClose,
+		'?': TokenQuestion,
+		'.': TokenDot,
+		'=': TokenBackreference,
+		'|': TokenPipe,
+		'+': TokenPlus,
+		'$': TokenEnd,
+	}
+
+	for col := 0; col < len(filename); col++ {
+		c, size := utf8.DecodeRuneInString(filename[col:])
+		switch {
+		case isLetter(c):
+			end := col
+			for end < len(filename) {
+				r, size := utf8.DecodeRuneInString(filename[end:])
+				if !isLetter(r) {
+					break
+				}
+				end += size
+			}
+			tokens = append(tokens, Token{TokenIdentifier, filename[col:end], l.line, offset + col})
+			col = end - 1
+		case c == '{':
+			end, e := inlineEnd(filename, col)
+			if e != nil {
+				return l.fail(tokens, Token{TokenError, filename[col:], l.line, offset + col}, fmt.Errorf("%v at line %d, column %d", e, l.line, offset+col))
+			}
+			tokens = append(tokens, Token{TokenInline, filename[col+1 : end], l.line, offset + col})
+			col = end
+		case c == '\\':
+			var seg Segment
+			end, e := readLiteral(filename, col, &seg)
+			if e != nil {
+				return l.fail(tokens, Token{TokenError, filename[col:], l.line, offset + col}, fmt.Errorf("%v at line %d, column %d", e, l.line, offset+col))
+			}
+			tokens = append(tokens, Token{TokenLiteral, string(*seg.Value), l.line, offset + col})
+			col = end
+		case single[c] != TokenEOF:
+			tokens = append(tokens, Token{single[c], string(c), l.line, offset + col})
+		default:
+			tokens, err = l.fail(tokens, Token{TokenError, string(c), l.line, offset + col}, fmt.Errorf("unexpected character `%c` at line %d, column %d", c, l.line, offset+col))
+			if err != nil {
+				return
+			}
+			col += size - 1
+		}
+	}
+	return
+}
+
+// fail reports an error, either as the given TokenError token when
+// recovering, or as a SyntaError located at the token
+func (l *Lexer) fail(tokens []Token, tok Token, e error) ([]Token, error) {
+	if !l.Recover {
+		return tokens, SyntaError{tok.Line, tok.Pos, e.Error(), tok.Type}
+	}
+	return append(tokens, tok), nil
+}
@@ -0,0 +1,232 @@
+package synta
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func lexAll(t *testing.T, l *Lexer) (tokens []Token) {
+	for {
+		tok, err := l.NextToken()
+		assert.Nil(t, err)
+		tokens = append(tokens, tok)
+		if tok.Type == TokenEOF || err != nil {
+			return
+		}
+	}
+}
+
+func TestLexer(t *testing.T) {
+	l := NewLexer(`; a name
+name = [a-z]+
+! require tag unless name
+> name(-tag)?.ext`, Options{})
+	assert.Equal(t, []Token{
+		{TokenComment, "a name", 1, 0},
+		{TokenIdentifier, "name", 2, 0},
+		{TokenAssign, "=", 2, 5},
+		{TokenPattern, "[a-z]+", 2, 7},
+		{TokenDirective, "require tag unless name", 3, 0},
+		{TokenFilename, ">", 4, 0},
+		{TokenIdentifier, "name", 4, 2},
+		{TokenOpen, "(", 4, 6},
+		{TokenDash, "-", 4, 7},
+		{TokenIdentifier, "tag", 4, 8},
+		{TokenClose, ")", 4, 11},
+		{TokenQuestion, "?", 4, 12},
+		{TokenDot, ".", 4, 13},
+		{TokenIdentifier, "ext", 4, 14},
+		{TokenEOF, "", 4, 0},
+	}, lexAll(t, l))
+}
+
+func TestLexerWithCRLF(t *testing.T) {
+	l := NewLexer("name = [a-z]+\r\next = pdf\r> name.ext\r\n", Options{})
+	tokens := lexAll(t, l)
+	assert.Equal(t, Token{TokenPattern, "[a-z]+", 1, 7}, tokens[2])
+	assert.Equal(t, Token{TokenPattern, "pdf", 2, 6}, tokens[5])
+	assert.Equal(t, Token{TokenIdentifier, "ext", 3, 7}, tokens[len(tokens)-2])
+}
+
+func TestLexerFailsOnUnknownCharacter(t *testing.T) {
+	l := NewLexer("> name_x.ext", Options{})
+	tok, err := l.NextToken()
+	assert.Nil(t, err)
+	assert.Equal(t, TokenFilename, tok.Type)
+	tok, err = l.NextToken()
+	assert.Nil(t, err)
+	assert.Equal(t, Token{TokenIdentifier, "name", 1, 2}, tok)
+
+	_, err = l.NextToken()
+	assert.EqualError(t, err, "unexpected character `_` at line 1, column 6")
+	_, err = l.NextToken()
+	assert.NotNil(t, err)
+}
+
+func TestLexerFailsOnInvalidIdentifier(t *testing.T) {
+	l := NewLexer("name = [a-z]+\n  name2 = [0-9]+", Options{})
+	for i := 0; i < 3; i++ {
+		_, err := l.NextToken()
+		assert.Nil(t, err)
+	}
+	_, err := l.NextToken()
+	var se SyntaError
+	assert.ErrorAs(t, err, &se)
+	assert.Equal(t, SyntaError{2, 6, "unexpected character `2` in identifier at line 2, column 6", TokenIdentifier}, se)
+
+	_, err = NewLexer("name [a-z]+", Options{}).NextToken()
+	assert.ErrorAs(t, err, &se)
+	assert.Equal(t, SyntaError{1, 11, "expected ` = ` at line 1, column 11", TokenError}, se)
+
+	l = NewLexer("name2 = [0-9]+", Options{})
+	l.Recover = true
+	assert.Equal(t, []Token{
+		{TokenError, "name2", 1, 0},
+		{TokenAssign, "=", 1, 6},
+		{TokenPattern, "[0-9]+", 1, 8},
+		{TokenEOF, "", 1, 0},
+	}, lexAll(t, l))
+}
+
+func TestLexerWithUnicodeIdentifiers(t *testing.T) {
+	assert.Equal(t, []Token{
+		{TokenIdentifier, "Città", 1, 0},
+		{TokenAssign, "=", 1, 7},
+		{TokenPattern, "[a-z]+", 1, 9},
+		{TokenFilename, ">", 2, 0},
+		{TokenIdentifier, "Città", 2, 2},
+		{TokenDot, ".", 2, 8},
+		{TokenIdentifier, "Città", 2, 9},
+		{TokenEOF, "", 2, 0},
+	}, lexAll(t, NewLexer("Città = [a-z]+\n> Città.Città", Options{})))
+
+	_, err := NewLexer("città2 = [0-9]+", Options{}).NextToken()
+	var se SyntaError
+	assert.ErrorAs(t, err, &se)
+	assert.Equal(t, SyntaError{1, 6, "unexpected character `2` in identifier at line 1, column 6", TokenIdentifier}, se)
+}
+
+func TestLexerWithContinuedPattern(t *testing.T) {
+	assert.Equal(t, []Token{
+		{TokenIdentifier, "ext", 1, 0},
+		{TokenAssign, "=", 1, 4},
+		{TokenPattern, "pdf|", 1, 6},
+		{TokenPattern, "txt|", 2, 2},
+		{TokenPattern, "md", 3, 2},
+		{TokenFilename, ">", 4, 0},
+		{TokenIdentifier, "ext", 4, 2},
+		{TokenDot, ".", 4, 5},
+		{TokenIdentifier, "ext", 4, 6},
+		{TokenEOF, "", 4, 0},
+	}, lexAll(t, NewLexer("ext = pdf|\\\n  txt|\\\n  md\n> ext.ext", Options{})))
+
+	l := NewLexer("ext = pdf|\\", Options{})
+	var err error
+	for err == nil {
+		_, err = l.NextToken()
+	}
+	assert.Equal(t, SyntaError{1, 10, "dangling `\\` continuation at the end of the file", TokenPattern}, err)
+}
+
+func TestLexerWithTrailingPatternComment(t *testing.T) {
+	assert.Equal(t, []Token{
+		{TokenIdentifier, "id", 1, 0},
+		{TokenAssign, "=", 1, 3},
+		{TokenPattern, "[ ;0-9]+", 1, 5},
+		{TokenComment, "the id", 1, 14},
+		{TokenEOF, "", 1, 0},
+	}, lexAll(t, NewLexer("id = [ ;0-9]+ ; the id", Options{})))
+}
+
+func TestLexerRecover(t *testing.T) {
+	l := NewLexer("> name_x-{[0-9]}.ext", Options{})
+	l.Recover = true
+	assert.Equal(t, []Token{
+		{TokenFilename, ">", 1, 0},
+		{TokenIdentifier, "name", 1, 2},
+		{TokenError, "_", 1, 6},
+		{TokenIdentifier, "x", 1, 7},
+		{TokenDash, "-", 1, 8},
+		{TokenInline, "[0-9]", 1, 9},
+		{TokenDot, ".", 1, 16},
+		{TokenIdentifier, "ext", 1, 17},
+		{TokenEOF, "", 1, 0},
+	}, lexAll(t, l))
+
+	l = NewLexer("name [a-z]+\n> name.ext", Options{})
+	l.Recover = true
+	tokens := lexAll(t, l)
+	assert.Equal(t, Token{TokenError, "name [a-z]+", 1, 11}, tokens[0])
+	assert.Equal(t, TokenFilename, tokens[1].Type)
+}
+
+func TestLexerExtensionComment(t *testing.T) {
+	l := NewLexer("> name.ext ; the format", Options{})
+	assert.Equal(t, []Token{
+		{TokenFilename, ">", 1, 0},
+		{TokenIdentifier, "name", 1, 2},
+		{TokenDot, ".", 1, 6},
+		{TokenIdentifier, "ext", 1, 7},
+		{TokenComment, "the format", 1, 11},
+		{TokenEOF, "", 1, 0},
+	}, lexAll(t, l))
+}
+
+func TestLexerAlternation(t *testing.T) {
+	l := NewLexer("> (lesson|lab).ext", Options{})
+	assert.Equal(t, []Token{
+		{TokenFilename, ">", 1, 0},
+		{TokenOpen, "(", 1, 2},
+		{TokenIdentifier, "lesson", 1, 3},
+		{TokenPipe, "|", 1, 9},
+		{TokenIdentifier, "lab", 1, 10},
+		{TokenClose, ")", 1, 13},
+		{TokenDot, ".", 1, 14},
+		{TokenIdentifier, "ext", 1, 15},
+		{TokenEOF, "", 1, 0},
+	}, lexAll(t, l))
+}
+
+func TestTokenTypeString(t *testing.T) {
+	assert.Equal(t, "Identifier", TokenIdentifier.String())
+	assert.Equal(t, "TokenType(99)", TokenType(99).String())
+}
+
+func TestLexerWithLiteral(t *testing.T) {
+	assert.Equal(t, []Token{
+		{TokenFilename, ">", 1, 0},
+		{TokenIdentifier, "major", 1, 2},
+		{TokenLiteral, ".-", 1, 7},
+		{TokenIdentifier, "minor", 1, 11},
+		{TokenDot, ".", 1, 16},
+		{TokenIdentifier, "ext", 1, 17},
+		{TokenEOF, "", 1, 0},
+	}, lexAll(t, NewLexer(`> major\.\-minor.ext`, Options{})))
+}
+
+func TestTokenize(t *testing.T) {
+	tokens, err := Tokenize(strings.NewReader("year = [0-9]{4}\n> name(-year)?.ext"))
+	assert.Nil(t, err)
+	assert.Equal(t, []Token{
+		{TokenIdentifier, "year", 1, 0},
+		{TokenAssign, "=", 1, 5},
+		{TokenPattern, "[0-9]{4}", 1, 7},
+		{TokenFilename, ">", 2, 0},
+		{TokenIdentifier, "name", 2, 2},
+		{TokenOpen, "(", 2, 6},
+		{TokenDash, "-", 2, 7},
+		{TokenIdentifier, "year", 2, 8},
+		{TokenClose, ")", 2, 12},
+		{TokenQuestion, "?", 2, 13},
+		{TokenDot, ".", 2, 14},
+		{TokenIdentifier, "ext", 2, 15},
+		{TokenEOF, "", 2, 0},
+	}, tokens)
+	assert.Equal(t, "Question", tokens[9].Type.String())
+
+	tokens, err = Tokenize(strings.NewReader("> name_x.ext"))
+	assert.Equal(t, []Token{{TokenFilename, ">", 1, 0}, {TokenIdentifier, "name", 1, 2}}, tokens)
+	assert.Equal(t, SyntaError{1, 6, "unexpected character `_` at line 1, column 6", TokenError}, err)
+}
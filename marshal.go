@@ -0,0 +1,131 @@
+package synta
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+)
+
+// definitionJSON is the JSON representation of a Definition, whose regexp is
+// stored as its source
+type definitionJSON struct {
+	Comments []string `json:"comments,omitempty"`
+	Pattern  string   `json:"pattern"`
+	MinLen   int      `json:"minLen,omitempty"`
+	MaxLen   int      `json:"maxLen,omitempty"`
+}
+
+// MarshalJSON encodes the definition, storing its regexp as its source
+func (d Definition) MarshalJSON() ([]byte, error) {
+	return json.Marshal(definitionJSON{d.Comments, d.Source(), d.MinLen, d.MaxLen})
+}
+
+// UnmarshalJSON decodes the definition, compiling its regexp
+func (d *Definition) UnmarshalJSON(data []byte) (err error) {
+	var raw definitionJSON
+	if err = json.Unmarshal(data, &raw); err != nil {
+		return
+	}
+	expr, err := regexp.Compile(raw.Pattern)
+	if err != nil {
+		return fmt.Errorf("invalid pattern `%s`: %v", raw.Pattern, err)
+	}
+	*d = Definition{Comments: raw.Comments, Regexp: expr, Pattern: raw.Pattern, MinLen: raw.MinLen, MaxLen: raw.MaxLen}
+	return
+}
+
+// segmentJSON is the JSON representation of a Segment, whose kind is stored
+// by name
+type segmentJSON struct {
+	Kind        string      `json:"kind"`
+	Value       *Identifier `json:"value,omitempty"`
+	Inline      *Definition `json:"inline,omitempty"`
+	Subsegments []Segment   `json:"subsegments,omitempty"`
+}
+
+// MarshalJSON encodes the segment, storing its kind by name, such as
+// "Identifier" or "Optional"
+func (seg Segment) MarshalJSON() ([]byte, error) {
+	return json.Marshal(segmentJSON{seg.Kind.String(), seg.Value, seg.Inline, seg.Subsegments})
+}
+
+// UnmarshalJSON decodes the segment, failing on unknown kinds
+func (seg *Segment) UnmarshalJSON(data []byte) (err error) {
+	var raw segmentJSON
+	if err = json.Unmarshal(data, &raw); err != nil {
+		return
+	}
+	for kind, name := range segmentTypeNames {
+		if name == raw.Kind {
+			*seg = Segment{SegmentType(kind), raw.Value, raw.Subsegments, raw.Inline}
+			return
+		}
+	}
+	return fmt.Errorf("unknown segment kind `%s`", raw.Kind)
+}
+
+// filenameJSON is the JSON representation of a Filename
+type filenameJSON struct {
+	Segments          []Segment    `json:"segments"`
+	Extension         Identifier   `json:"extension"`
+	Extensions        []Identifier `json:"extensions,omitempty"`
+	Comments          []string     `json:"comments,omitempty"`
+	ExtensionComments []string     `json:"extensionComments,omitempty"`
+}
+
+// MarshalJSON encodes the filename
+func (f Filename) MarshalJSON() ([]byte, error) {
+	return json.Marshal(filenameJSON(f))
+}
+
+// UnmarshalJSON decodes the filename
+func (f *Filename) UnmarshalJSON(data []byte) (err error) {
+	var raw filenameJSON
+	if err = json.Unmarshal(data, &raw); err == nil {
+		*f = Filename(raw)
+	}
+	return
+}
+
+// syntaJSON is the JSON representation of a Synta
+type syntaJSON struct {
+	Definitions    map[Identifier]Definition `json:"definitions"`
+	Filename       Filename                  `json:"filename"`
+	Constraints    []Constraint              `json:"constraints,omitempty"`
+	Key            Identifier                `json:"key,omitempty"`
+	LowercaseInput bool                      `json:"lowercaseInput,omitempty"`
+	MatchStrategy  MatchStrategy             `json:"matchStrategy,omitempty"`
+	Separator      string                    `json:"separator,omitempty"`
+	Alternatives   []Filename                `json:"alternatives,omitempty"`
+}
+
+// MarshalJSON encodes the spec. Nodes are not encoded, so the order of the
+// declarations is lost.
+//
+// The encoding is described by JSONSchema and holds every part of the spec,
+// so UnmarshalJSON can read it back. It is unrelated to the json package,
+// which converts specs into the older format printed by `synta json`: that
+// format stores kinds as numbers and only holds the definitions and the
+// filename.
+func (s Synta) MarshalJSON() ([]byte, error) {
+	return json.Marshal(syntaJSON{s.Definitions, s.Filename, s.Constraints, s.key, s.LowercaseInput, s.MatchStrategy, s.Separator, s.Alternatives})
+}
+
+// UnmarshalJSON decodes the spec, compiling the regexps of its definitions
+func (s *Synta) UnmarshalJSON(data []byte) (err error) {
+	var raw syntaJSON
+	if err = json.Unmarshal(data, &raw); err != nil {
+		return
+	}
+	*s = Synta{
+		Definitions:    raw.Definitions,
+		Filename:       raw.Filename,
+		Constraints:    raw.Constraints,
+		LowercaseInput: raw.LowercaseInput,
+		MatchStrategy:  raw.MatchStrategy,
+		Separator:      raw.Separator,
+		Alternatives:   raw.Alternatives,
+		key:            raw.Key,
+	}
+	return
+}
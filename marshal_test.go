@@ -0,0 +1,69 @@
+package synta
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshalJSON(t *testing.T) {
+	synta := MustSynta(`; the course name
+course = [a-z]+
+year = [0-9]{4} len(4,4)
+tag = [a-z]+
+author = [a-z]+
+ext = pdf|txt
+! require tag unless author
+! key = course
+; the exams
+> course-year(-tag)?(-author(-=tag)?)?-{v[0-9]}.ext`)
+
+	data, err := json.Marshal(synta)
+	assert.Nil(t, err)
+
+	var decoded Synta
+	assert.Nil(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, synta.Definitions, decoded.Definitions)
+	assert.Equal(t, synta.Filename, decoded.Filename)
+	assert.Equal(t, synta.Constraints, decoded.Constraints)
+	assert.Equal(t, synta.key, decoded.key)
+
+	values, err := decoded.Extract("algebra-2024-exam-v1.pdf")
+	assert.Nil(t, err)
+	assert.Equal(t, "exam", values["tag"])
+}
+
+func TestMarshalSegmentJSON(t *testing.T) {
+	name := Identifier("name")
+	data, err := json.Marshal(Segment{Kind: SegmentTypeOptional, Subsegments: []Segment{{Kind: SegmentTypeIdentifier, Value: &name}}})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"kind":"Optional","subsegments":[{"kind":"Identifier","value":"name"}]}`, string(data))
+
+	var seg Segment
+	assert.NotNil(t, json.Unmarshal([]byte(`{"kind":"Unknown"}`), &seg))
+	var def Definition
+	assert.NotNil(t, json.Unmarshal([]byte(`{"pattern":"[a-z"}`), &def))
+}
+
+func TestMarshalConstraintJSON(t *testing.T) {
+	data, err := json.Marshal(Constraint{Kind: ConstraintRequiredUnless, Subject: "tag", Other: "author"})
+	assert.Nil(t, err)
+	assert.Equal(t, `{"kind":"RequiredUnless","subject":"tag","other":"author"}`, string(data))
+
+	var c Constraint
+	assert.Nil(t, json.Unmarshal([]byte(`{"kind":"Equal","subject":"a","other":"b"}`), &c))
+	assert.Equal(t, Constraint{Kind: ConstraintEqual, Subject: "a", Other: "b"}, c)
+	assert.EqualError(t, json.Unmarshal([]byte(`{"kind":"Unknown"}`), &c), "unknown constraint kind `Unknown`")
+
+	data, err = json.Marshal(Synta{MatchStrategy: FirstVariant})
+	assert.Nil(t, err)
+	var encoded map[string]any
+	assert.Nil(t, json.Unmarshal(data, &encoded))
+	assert.Equal(t, "FirstVariant", encoded["matchStrategy"])
+
+	var strategy MatchStrategy
+	assert.Nil(t, json.Unmarshal([]byte(`"PreferMostOptionals"`), &strategy))
+	assert.Equal(t, PreferMostOptionals, strategy)
+	assert.EqualError(t, json.Unmarshal([]byte(`"Unknown"`), &strategy), "unknown match strategy `Unknown`")
+}
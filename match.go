@@ -0,0 +1,109 @@
+package synta
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+)
+
+// Match tells whether the whole filename conforms to the spec, constraints
+// included. An error is returned only when the regexp of the spec cannot be
+// built, because of a missing definition or an invalid regexp.
+func (s Synta) Match(filename string) (matches bool, err error) {
+	m, err := s.Matcher()
+	if err != nil {
+		return
+	}
+	matches = m.Match(filename)
+	return
+}
+
+// MatchFold works like Match, but ignores the case of the filename, so that
+// `Lesson-01.PDF` matches a spec written in lowercase. The definitions are
+// left as authored, only their regexp is compiled case-insensitively.
+func (s Synta) MatchFold(filename string) (matches bool, err error) {
+	m, err := s.Matcher()
+	if err != nil {
+		return
+	}
+	if err = m.fold(); err != nil {
+		return
+	}
+	matches = m.Match(filename)
+	return
+}
+
+// fold recompiles the regexps of the Matcher, the ones of its alternatives
+// included, so that they ignore the case of the filename
+func (m *Matcher) fold() (err error) {
+	if m.expr.expr, err = regexp.Compile("(?i)" + m.expr.expr.String()); err != nil {
+		return
+	}
+	for _, alternative := range m.alternatives {
+		if err = alternative.fold(); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// MatchAll partitions the filenames into the ones conforming to the spec and
+// the others, keeping their order. The regexp of the spec is compiled once
+// for all the filenames, and an error is returned only when it cannot be
+// built.
+func (s Synta) MatchAll(filenames []string) (matching []string, nonMatching []string, err error) {
+	m, err := s.Matcher()
+	if err != nil {
+		return
+	}
+	for _, filename := range filenames {
+		if m.Match(filename) {
+			matching = append(matching, filename)
+		} else {
+			nonMatching = append(nonMatching, filename)
+		}
+	}
+	return
+}
+
+// MatchWithExtensions tells whether the filename conforms to the spec and its
+// extension is one of the allowed ones, which restricts the extensions
+// accepted by the spec at runtime. Every allowed extension must be accepted
+// by the spec's extension definition, otherwise an error is returned.
+func (s Synta) MatchWithExtensions(filename string, allowed []Identifier) (matches bool, err error) {
+	for _, id := range s.Filename.extensions() {
+		if _, ok := s.Definitions[id]; !ok {
+			err = fmt.Errorf("missing definition for `%s`", id)
+			return
+		}
+	}
+	for _, ext := range allowed {
+		accepted := false
+		for _, id := range s.Filename.extensions() {
+			ok, e := matchesDefinition(s.Definitions[id], string(ext))
+			if e != nil {
+				err = e
+				return
+			}
+			accepted = accepted || ok
+		}
+		if !accepted {
+			err = fmt.Errorf("extension `%s` is not accepted by the spec", ext)
+			return
+		}
+	}
+
+	if _, err = s.BuildRegexp(); err != nil {
+		return
+	}
+	values, e := s.Extract(filename)
+	if e != nil {
+		return false, nil
+	}
+	for _, id := range s.Filename.extensions() {
+		if value, ok := values[id]; ok {
+			matches = slices.Contains(allowed, Identifier(value))
+		}
+	}
+	return
+}
@@ -0,0 +1,186 @@
+package synta
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const multiExtensionInput = `name = [a-z]+
+ext = pdf|md|txt
+> name.ext`
+
+func TestMatchWithExtensions(t *testing.T) {
+	synta := MustSynta(multiExtensionInput)
+
+	matches, err := synta.MatchWithExtensions("notes.pdf", []Identifier{"pdf"})
+	assert.Nil(t, err)
+	assert.True(t, matches)
+
+	matches, err = synta.MatchWithExtensions("notes.md", []Identifier{"pdf"})
+	assert.Nil(t, err)
+	assert.False(t, matches)
+
+	matches, err = synta.MatchWithExtensions("notes.md", []Identifier{"pdf", "md"})
+	assert.Nil(t, err)
+	assert.True(t, matches)
+
+	matches, err = synta.MatchWithExtensions("Notes.pdf", []Identifier{"pdf"})
+	assert.Nil(t, err)
+	assert.False(t, matches)
+}
+
+func TestMatchWithUnknownExtensions(t *testing.T) {
+	synta := MustSynta(multiExtensionInput)
+
+	_, err := synta.MatchWithExtensions("notes.pdf", []Identifier{"pdf", "docx"})
+	assert.EqualError(t, err, "extension `docx` is not accepted by the spec")
+}
+
+func TestMatch(t *testing.T) {
+	synta := MustSynta(`type = lesson
+number = [0-9]{2}
+title = [a-z]+
+ext = pdf
+> type-number(-title)?.ext`)
+
+	for filename, expected := range map[string]bool{
+		"lesson-01.pdf":          true,
+		"lesson-01-intro.pdf":    true,
+		"lesson-1-intro.pdf":     false,
+		"lesson-01-intro.txt":    false,
+		"lesson-01-.pdf":         false,
+		"xlesson-01-intro.pdf":   false,
+		"lesson-01-intro.pdf.gz": false,
+	} {
+		matches, err := synta.Match(filename)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, matches, filename)
+	}
+
+	synta.Definitions["title"] = Definition{Pattern: "["}
+	_, err := synta.Match("lesson-01.pdf")
+	assert.NotNil(t, err)
+}
+
+func TestMatchFold(t *testing.T) {
+	synta := MustSynta(`type = lesson
+number = [0-9]{2}
+title = [a-z]+
+ext = pdf
+> type-number(-title)?.ext`)
+
+	matches, err := synta.MatchFold("Lesson-01.PDF")
+	assert.Nil(t, err)
+	assert.True(t, matches)
+	matches, err = synta.MatchFold("LESSON-01-Intro.pdf")
+	assert.Nil(t, err)
+	assert.True(t, matches)
+	matches, err = synta.MatchFold("Lesson-1.PDF")
+	assert.Nil(t, err)
+	assert.False(t, matches)
+
+	matches, err = synta.Match("Lesson-01.PDF")
+	assert.Nil(t, err)
+	assert.False(t, matches)
+	assert.Equal(t, "lesson", synta.Definitions["type"].Source())
+
+	synta.Definitions["title"] = Definition{Pattern: "["}
+	_, err = synta.MatchFold("lesson-01.pdf")
+	assert.NotNil(t, err)
+}
+
+func TestMatchFoldWithAlternatives(t *testing.T) {
+	synta, err := ParseSyntaWithOptions(`type = lesson
+number = [0-9]{2}
+title = [a-z]+
+ext = pdf
+> type-number.ext
+> title.ext`, Options{MultipleFilenames: true})
+	assert.Nil(t, err)
+
+	matches, err := synta.MatchFold("Intro.PDF")
+	assert.Nil(t, err)
+	assert.True(t, matches)
+	matches, err = synta.MatchFold("Lesson-01.pdf")
+	assert.Nil(t, err)
+	assert.True(t, matches)
+}
+
+func TestMatchWithExtensionAlternation(t *testing.T) {
+	synta := MustSynta(`name = [a-z]+
+jpg = jpe?g
+png = png
+gif = gif
+> name.(jpg|png|gif)`)
+
+	for filename, expected := range map[string]bool{
+		"photo.jpg":  true,
+		"photo.jpeg": true,
+		"photo.png":  true,
+		"photo.gif":  true,
+		"photo.bmp":  false,
+		"photo.":     false,
+	} {
+		matches, err := synta.Match(filename)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, matches, filename)
+	}
+
+	values, err := synta.Extract("photo.png")
+	assert.Nil(t, err)
+	assert.Equal(t, map[Identifier]string{"name": "photo", "png": "png"}, values)
+
+	matches, err := synta.MatchWithExtensions("photo.gif", []Identifier{"png", "gif"})
+	assert.Nil(t, err)
+	assert.True(t, matches)
+	matches, err = synta.MatchWithExtensions("photo.jpg", []Identifier{"png", "gif"})
+	assert.Nil(t, err)
+	assert.False(t, matches)
+}
+
+func TestMatchAll(t *testing.T) {
+	synta := MustSynta(extractInput)
+
+	matching, nonMatching, err := synta.MatchAll([]string{
+		"analisi-2024.pdf",
+		"analisi-24.pdf",
+		"analisi-2024-esame.txt",
+		"Analisi-2024.pdf",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"analisi-2024.pdf", "analisi-2024-esame.txt"}, matching)
+	assert.Equal(t, []string{"analisi-24.pdf", "Analisi-2024.pdf"}, nonMatching)
+
+	_, _, err = Synta{Filename: Filename{Extension: "ext"}}.MatchAll([]string{"a.ext"})
+	assert.NotNil(t, err)
+}
+
+func benchmarkFilenames() (filenames []string) {
+	for i := 0; i < 1000; i++ {
+		if i%2 == 0 {
+			filenames = append(filenames, "analisi-2024-esame.pdf")
+		} else {
+			filenames = append(filenames, "analisi-24.pdf")
+		}
+	}
+	return
+}
+
+func BenchmarkMatchAll(b *testing.B) {
+	synta := MustSynta(extractInput)
+	filenames := benchmarkFilenames()
+	for i := 0; i < b.N; i++ {
+		synta.MatchAll(filenames)
+	}
+}
+
+func BenchmarkMatchEach(b *testing.B) {
+	synta := MustSynta(extractInput)
+	filenames := benchmarkFilenames()
+	for i := 0; i < b.N; i++ {
+		for _, filename := range filenames {
+			synta.Match(filename)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package synta
+
+import (
+	"fmt"
+	"sync"
+)
+
+// A Matcher checks filenames against a spec, reusing the regexp compiled
+// once when the Matcher is created. It is safe for concurrent use.
+type Matcher struct {
+	synta Synta
+	expr  captureRegexp
+	// alternatives match the Alternatives of the spec, tried in order when
+	// the filename does not conform to its Filename
+	alternatives []*Matcher
+	// strategy extracts the values of ambiguous filenames when the spec's
+	// MatchStrategy is not MatchGreedy
+	strategy *strategyMatcher
+}
+
+// Matcher compiles the spec into a Matcher. Missing definitions and invalid
+// regexps are reported here, rather than on each match.
+func (s Synta) Matcher() (m *Matcher, err error) {
+	expr, err := s.buildCaptureRegexp()
+	if err != nil {
+		return
+	}
+	m = &Matcher{synta: s, expr: expr}
+	if s.MatchStrategy != MatchGreedy {
+		if m.strategy, err = s.strategyMatcher(); err != nil {
+			return nil, err
+		}
+	}
+	for _, filename := range s.Alternatives {
+		alternative := s
+		alternative.Filename, alternative.Alternatives = filename, nil
+		am, e := alternative.Matcher()
+		if e != nil {
+			return nil, e
+		}
+		m.alternatives = append(m.alternatives, am)
+	}
+	return
+}
+
+// Match tells whether the filename conforms to the spec, constraints
+// included
+func (m *Matcher) Match(filename string) bool {
+	return m.Captures(filename) != nil
+}
+
+// Captures returns the value captured by each segment of the filename, keyed
+// as by Synta.Captures, or nil if the filename does not conform to the spec.
+// The alternative filenames are tried in order after the main one.
+func (m *Matcher) Captures(filename string) map[Identifier]string {
+	captures, values := m.expr.match(m.synta.input(filename))
+	if captures != nil && m.synta.checkCaptures(values) == nil {
+		return captures
+	}
+	for _, alternative := range m.alternatives {
+		if captures = alternative.Captures(filename); captures != nil {
+			return captures
+		}
+	}
+	return nil
+}
+
+// Extract returns the value captured for each identifier of the filename, as
+// Synta.Extract does, without compiling the regexps of the spec again. The
+// alternative filenames are tried in order after the main one.
+func (m *Matcher) Extract(filename string) (values map[Identifier]string, err error) {
+	if values, err = m.extract(filename); err == nil {
+		return
+	}
+	for _, alternative := range m.alternatives {
+		if values, e := alternative.extract(filename); e == nil {
+			return values, nil
+		}
+	}
+	return
+}
+
+// extract works like Extract, ignoring the alternatives
+func (m *Matcher) extract(filename string) (values map[Identifier]string, err error) {
+	if m.strategy != nil {
+		return m.strategy.extract(filename)
+	}
+	captures, all := m.expr.match(m.synta.input(filename))
+	if captures == nil || m.synta.checkCaptures(all) != nil {
+		err = fmt.Errorf("filename `%s` does not match the spec", filename)
+		return
+	}
+	values = map[Identifier]string{}
+	for id, found := range all {
+		values[id] = found[0]
+	}
+	return
+}
+
+// MatchBatch matches the filenames concurrently, spreading them over the
+// given number of workers, and returns the results in the same order as the
+// filenames. A single worker is used when workers is not positive.
+func (m *Matcher) MatchBatch(filenames []string, workers int) (matches []bool) {
+	matches = make([]bool, len(filenames))
+	workers = max(1, min(workers, len(filenames)))
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				matches[i] = m.Match(filenames[i])
+			}
+		}()
+	}
+	for i := range filenames {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	return
+}
@@ -0,0 +1,99 @@
+package synta
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatcher(t *testing.T) {
+	synta, err := ParseSynta(extractInput)
+	assert.Nil(t, err)
+
+	m, err := synta.Matcher()
+	assert.Nil(t, err)
+	assert.True(t, m.Match("analisi-2024-esame.pdf"))
+	assert.True(t, m.Match("analisi-2024.txt"))
+	assert.False(t, m.Match("analisi-24.pdf"))
+
+	_, err = Synta{Filename: Filename{Extension: "ext"}}.Matcher()
+	assert.NotNil(t, err)
+}
+
+func TestMatcherExtract(t *testing.T) {
+	synta, err := ParseSyntaWithOptions(`course = [a-z]+
+year = [0-9]{4}
+tag = [a-z]+
+ext = pdf
+> course-year(-tag)?.ext
+> course-tag.ext`, Options{MultipleFilenames: true})
+	assert.Nil(t, err)
+	m, err := synta.Matcher()
+	assert.Nil(t, err)
+
+	values, err := m.Extract("analisi-2024-esame.pdf")
+	assert.Nil(t, err)
+	assert.Equal(t, map[Identifier]string{"course": "analisi", "year": "2024", "tag": "esame", "ext": "pdf"}, values)
+	values, err = m.Extract("analisi-esame.pdf")
+	assert.Nil(t, err)
+	assert.Equal(t, map[Identifier]string{"course": "analisi", "tag": "esame", "ext": "pdf"}, values)
+	_, err = m.Extract("analisi.pdf")
+	assert.EqualError(t, err, "filename `analisi.pdf` does not match the spec")
+}
+
+func TestMatcherMatchBatch(t *testing.T) {
+	synta, err := ParseSynta(extractInput)
+	assert.Nil(t, err)
+	m, err := synta.Matcher()
+	assert.Nil(t, err)
+
+	filenames := []string{}
+	expected := []bool{}
+	for i := 0; i < 100; i++ {
+		if i%3 == 0 {
+			filenames = append(filenames, "analisi-24.pdf")
+			expected = append(expected, false)
+		} else {
+			filenames = append(filenames, "analisi-2024.pdf")
+			expected = append(expected, true)
+		}
+	}
+
+	assert.Equal(t, expected, m.MatchBatch(filenames, 8))
+	assert.Equal(t, expected, m.MatchBatch(filenames, 0))
+	assert.Equal(t, []bool{}, m.MatchBatch(nil, 4))
+}
+
+func TestMatcherCaptures(t *testing.T) {
+	synta, err := ParseSynta(extractInput)
+	assert.Nil(t, err)
+	m, err := synta.Matcher()
+	assert.Nil(t, err)
+
+	assert.Equal(t, map[Identifier]string{"course": "analisi", "year": "2024", "tag": "esame", "ext": "pdf"}, m.Captures("analisi-2024-esame.pdf"))
+	assert.Equal(t, map[Identifier]string{"course": "analisi", "year": "2024", "ext": "txt"}, m.Captures("analisi-2024.txt"))
+	assert.Nil(t, m.Captures("analisi-24.pdf"))
+
+	synta, err = ParseSynta("name = [a-z]+\n> name-name.name")
+	assert.Nil(t, err)
+	m, err = synta.Matcher()
+	assert.Nil(t, err)
+	assert.Equal(t, map[Identifier]string{"name": "a", "name_2": "b", "name_3": "c"}, m.Captures("a-b.c"))
+}
+
+func BenchmarkMatcherMatch(b *testing.B) {
+	m, err := MustSynta(extractInput).Matcher()
+	if err != nil {
+		b.Fatal(err)
+	}
+	for i := 0; i < b.N; i++ {
+		m.Match("analisi-2024-esame.pdf")
+	}
+}
+
+func BenchmarkSyntaMatch(b *testing.B) {
+	synta := MustSynta(extractInput)
+	for i := 0; i < b.N; i++ {
+		synta.Match("analisi-2024-esame.pdf")
+	}
+}
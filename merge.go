@@ -0,0 +1,71 @@
+package synta
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// Merge combines the specs into a single one, such as a file of shared
+// definitions, parsed with Options.DefinitionsOnly, and the spec declaring the
+// filename. Definitions are united, and an identifier defined by more than
+// one spec must have the same regexp and length bounds in each of them, the
+// first definition being kept. Exactly one spec must declare a filename, and
+// its alternatives, options, key and separator are kept; the definitions it
+// references may come from any spec. Nodes list the definitions spec by spec,
+// in declaration order, followed by the filename and its alternatives.
+func Merge(specs ...Synta) (merged Synta, err error) {
+	var filename *Synta
+	for i := range specs {
+		if len(specs[i].Filename.Segments) == 0 {
+			continue
+		}
+		if filename != nil {
+			err = errors.New("more than one spec declares a filename")
+			return
+		}
+		filename = &specs[i]
+	}
+	if filename == nil {
+		err = errors.New("no spec declares a filename")
+		return
+	}
+
+	merged = Synta{
+		Definitions:    map[Identifier]Definition{},
+		Filename:       filename.Filename,
+		Alternatives:   filename.Alternatives,
+		LowercaseInput: filename.LowercaseInput,
+		MatchStrategy:  filename.MatchStrategy,
+		Separator:      filename.Separator,
+		key:            filename.key,
+	}
+	for _, spec := range specs {
+		for _, id := range spec.declarationOrder() {
+			def := spec.Definitions[id]
+			if existing, ok := merged.Definitions[id]; ok {
+				if existing.Expression() != def.Expression() {
+					err = fmt.Errorf("conflicting definitions for `%s`: `%s` and `%s`", id, existing.Expression(), def.Expression())
+					return
+				}
+				continue
+			}
+			merged.Definitions[id] = def
+			merged.Nodes = append(merged.Nodes, Node{Type: NodeTypeDefinition, Identifier: id, Definition: &def})
+		}
+		for _, c := range spec.Constraints {
+			if !slices.Contains(merged.Constraints, c) {
+				merged.Constraints = append(merged.Constraints, c)
+			}
+		}
+	}
+	merged.Nodes = append(merged.Nodes, Node{Type: NodeTypeFilename, Filename: &merged.Filename})
+	for i := range merged.Alternatives {
+		merged.Nodes = append(merged.Nodes, Node{Type: NodeTypeFilename, Filename: &merged.Alternatives[i]})
+	}
+
+	if missing := merged.MissingDefinitions(); len(missing) > 0 {
+		err = fmt.Errorf("missing definition for `%s`", missing[0])
+	}
+	return
+}
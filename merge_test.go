@@ -0,0 +1,86 @@
+package synta
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge(t *testing.T) {
+	shared, err := ParseSyntaWithOptions(`; the year
+year = [0-9]{4}
+ext = pdf`, Options{DefinitionsOnly: true})
+	assert.Nil(t, err)
+	project := MustSynta(`course = [a-z]+
+year = [0-9]{4}
+ext = pdf
+> course-year.ext`)
+	delete(project.Definitions, "year")
+
+	merged, err := Merge(shared, project)
+	assert.Nil(t, err)
+	assert.Equal(t, project.Filename, merged.Filename)
+	assert.Equal(t, []string{"the year"}, merged.Definitions["year"].Comments)
+	assert.Len(t, merged.Definitions, 3)
+
+	order := []Identifier{}
+	for _, node := range merged.Nodes {
+		order = append(order, node.Identifier)
+	}
+	assert.Equal(t, []Identifier{"year", "ext", "course", ""}, order)
+
+	matches, err := merged.Match("analisi-2024.pdf")
+	assert.Nil(t, err)
+	assert.True(t, matches)
+
+	_, err = Merge(project)
+	assert.EqualError(t, err, "missing definition for `year`")
+}
+
+func TestMergeWithConflictingDefinitions(t *testing.T) {
+	shared, err := ParseSyntaWithOptions("year = [0-9]{2}", Options{DefinitionsOnly: true})
+	assert.Nil(t, err)
+	project := MustSynta("year = [0-9]{4}\next = pdf\n> year.ext")
+
+	_, err = Merge(shared, project)
+	assert.EqualError(t, err, "conflicting definitions for `year`: `[0-9]{2}` and `[0-9]{4}`")
+
+	shared, err = ParseSyntaWithOptions("name = [a-z]+ len(2,3)", Options{DefinitionsOnly: true})
+	assert.Nil(t, err)
+	project = MustSynta("name = [a-z]+\next = pdf\n> name.ext")
+	_, err = Merge(shared, project)
+	assert.EqualError(t, err, "conflicting definitions for `name`: `[a-z]+ len(2,3)` and `[a-z]+`")
+}
+
+func TestMergeWithoutSingleFilename(t *testing.T) {
+	shared, err := ParseSyntaWithOptions("year = [0-9]{4}", Options{DefinitionsOnly: true})
+	assert.Nil(t, err)
+	project := MustSynta("year = [0-9]{4}\next = pdf\n> year.ext")
+
+	_, err = Merge(shared)
+	assert.EqualError(t, err, "no spec declares a filename")
+	_, err = Merge(project, shared, project)
+	assert.EqualError(t, err, "more than one spec declares a filename")
+}
+
+func TestMergeWithAlternatives(t *testing.T) {
+	shared, err := ParseSyntaWithOptions(`year = [0-9]{4}
+ext = pdf`, Options{DefinitionsOnly: true})
+	assert.Nil(t, err)
+	project, err := ParseSyntaWithOptions(`course = [a-z]+
+year = [0-9]{4}
+ext = pdf
+> course-year.ext
+> course.ext`, Options{MultipleFilenames: true})
+	assert.Nil(t, err)
+
+	merged, err := Merge(shared, project)
+	assert.Nil(t, err)
+	assert.Equal(t, project.Alternatives, merged.Alternatives)
+	assert.Equal(t, NodeTypeFilename, merged.Nodes[len(merged.Nodes)-1].Type)
+	assert.Equal(t, "course.ext", merged.Nodes[len(merged.Nodes)-1].Filename.String())
+
+	matches, err := merged.Match("analisi.pdf")
+	assert.Nil(t, err)
+	assert.True(t, matches)
+}
@@ -0,0 +1,41 @@
+package synta
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MigrateFilename renames a filename following the old spec so that it
+// follows the new one: the values extracted with the old spec are rendered
+// with the new spec, matching identifiers by name. Values of identifiers the
+// new spec does not use, and identifiers the new spec requires but the
+// filename lacks, make the migration fail, as do inline segments of the new
+// spec matching more than one value, whose text cannot be rendered.
+func MigrateFilename(old, new Synta, filename string) (migrated string, err error) {
+	values, err := old.Extract(filename)
+	if err != nil {
+		return
+	}
+
+	known := map[Identifier]bool{}
+	for _, id := range new.Filename.identifiers() {
+		known[id] = true
+	}
+	unmapped := []string{}
+	for id := range values {
+		if !known[id] {
+			unmapped = append(unmapped, string(id))
+		}
+	}
+	if len(unmapped) > 0 {
+		sort.Strings(unmapped)
+		err = fmt.Errorf("the new spec has no place for `%s`", strings.Join(unmapped, "`, `"))
+		return
+	}
+
+	if migrated, err = new.Render(values); err != nil {
+		return "", fmt.Errorf("cannot migrate `%s`: %v", filename, err)
+	}
+	return
+}
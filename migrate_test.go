@@ -0,0 +1,57 @@
+package synta
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrateFilename(t *testing.T) {
+	old := MustSynta(`course = [a-z]+
+year = [0-9]{4}
+ext = pdf
+> course-year.ext`)
+	new := MustSynta(`course = [a-z]+
+year = [0-9]{4}
+tag = [a-z]+
+ext = pdf
+> year-course(-tag)?.ext`)
+
+	migrated, err := MigrateFilename(old, new, "analisi-2024.pdf")
+	assert.Nil(t, err)
+	assert.Equal(t, "2024-analisi.pdf", migrated)
+}
+
+func TestMigrateFilenameWithUnmappedFields(t *testing.T) {
+	old := MustSynta(`course = [a-z]+
+year = [0-9]{4}
+ext = pdf
+> course-year.ext`)
+	new := MustSynta(`course = [a-z]+
+author = [a-z]+
+ext = pdf
+> course-author.ext`)
+
+	_, err := MigrateFilename(old, new, "analisi-2024.pdf")
+	assert.EqualError(t, err, "the new spec has no place for `year`")
+
+	_, err = MigrateFilename(new, old, "analisi-rossi.pdf")
+	assert.EqualError(t, err, "the new spec has no place for `author`")
+
+	_, err = MigrateFilename(old, old, "analisi.pdf")
+	assert.NotNil(t, err)
+}
+
+func TestMigrateFilenameWithMissingFields(t *testing.T) {
+	old := MustSynta(`course = [a-z]+
+ext = pdf
+> course.ext`)
+	new := MustSynta(`course = [a-z]+
+year = [0-9]{4}
+ext = pdf
+> course-year.ext`)
+
+	migrated, err := MigrateFilename(old, new, "analisi.pdf")
+	assert.EqualError(t, err, "cannot migrate `analisi.pdf`: missing value for `year`")
+	assert.Equal(t, "", migrated)
+}
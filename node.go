@@ -0,0 +1,58 @@
+package synta
+
+// A TypedNode is a top level element of a Synta file, as a typed alternative
+// to Node which is told apart with a type switch: it is either a
+// DefinitionNode, matching the <commdef> BNF definition, or a FilenameNode,
+// matching the <filename> one. Node converts it back to the tagged struct.
+type TypedNode interface {
+	Node() Node
+}
+
+// A DefinitionNode is a definition of the file, along with its identifier
+type DefinitionNode struct {
+	Identifier Identifier
+	Definition Definition
+	Line       int
+}
+
+// Node returns the definition as a tagged Node
+func (n DefinitionNode) Node() Node {
+	def := n.Definition
+	return Node{Type: NodeTypeDefinition, Identifier: n.Identifier, Definition: &def, Line: n.Line}
+}
+
+// A FilenameNode is the filename declaration of the file
+type FilenameNode struct {
+	Filename Filename
+	Line     int
+}
+
+// Node returns the filename as a tagged Node
+func (n FilenameNode) Node() Node {
+	filename := n.Filename
+	return Node{Type: NodeTypeFilename, Filename: &filename, Line: n.Line}
+}
+
+// Typed returns the node as a DefinitionNode or a FilenameNode, according to
+// its Type, or nil when the pointer matching the Type is not set
+func (n Node) Typed() TypedNode {
+	switch {
+	case n.Type == NodeTypeDefinition && n.Definition != nil:
+		return DefinitionNode{n.Identifier, *n.Definition, n.Line}
+	case n.Type == NodeTypeFilename && n.Filename != nil:
+		return FilenameNode{*n.Filename, n.Line}
+	}
+	return nil
+}
+
+// TypedNodes returns the Nodes of the spec as typed nodes, in the order of
+// the file, skipping the ones which are not well formed
+func (s Synta) TypedNodes() (nodes []TypedNode) {
+	nodes = []TypedNode{}
+	for _, node := range s.Nodes {
+		if typed := node.Typed(); typed != nil {
+			nodes = append(nodes, typed)
+		}
+	}
+	return
+}
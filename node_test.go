@@ -0,0 +1,50 @@
+package synta
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTypedNodes(t *testing.T) {
+	synta := MustSynta(`; the name
+name = [a-z]+
+> name.ext
+ext = pdf`)
+
+	described := []string{}
+	for _, node := range synta.TypedNodes() {
+		switch n := node.(type) {
+		case DefinitionNode:
+			described = append(described, string(n.Identifier)+" = "+n.Definition.Source())
+		case FilenameNode:
+			described = append(described, "> "+n.Filename.String())
+		}
+		assert.Equal(t, node, node.Node().Typed())
+	}
+	assert.Equal(t, []string{"name = [a-z]+", "> name.ext", "ext = pdf"}, described)
+
+	assert.Nil(t, Node{Type: NodeTypeFilename}.Typed())
+	assert.Empty(t, Synta{Nodes: []Node{{Type: NodeTypeDefinition, Identifier: "name"}}}.TypedNodes())
+}
+
+func TestTypedNodesWithAlternatives(t *testing.T) {
+	synta, err := ParseSyntaWithOptions(`name = [a-z]+
+> name.ext
+year = [0-9]{4}
+> name-year.ext
+ext = pdf`, Options{MultipleFilenames: true})
+	assert.Nil(t, err)
+
+	described := []string{}
+	for _, node := range synta.TypedNodes() {
+		switch n := node.(type) {
+		case DefinitionNode:
+			described = append(described, fmt.Sprintf("%d: %s", n.Line, n.Identifier))
+		case FilenameNode:
+			described = append(described, fmt.Sprintf("%d: > %s", n.Line, n.Filename))
+		}
+	}
+	assert.Equal(t, []string{"1: name", "2: > name.ext", "3: year", "4: > name-year.ext", "5: ext"}, described)
+}
@@ -0,0 +1,32 @@
+package synta
+
+import (
+	"strings"
+)
+
+// OpenAPISchema returns an OpenAPI schema fragment describing the filenames
+// accepted by the spec: a string whose pattern is the spec's regexp, along with
+// an example filename and, when the filename is commented, a description. The
+// pattern only uses non-capturing groups, as named groups are written
+// differently across regexp dialects. The fragment only holds strings, so it
+// can be serialized to both JSON and YAML.
+func (s Synta) OpenAPISchema() (schema map[string]interface{}, err error) {
+	expr, err := s.BuildRegexpWith(func(id Identifier, def Definition) string {
+		return "(?:" + def.Source() + ")"
+	})
+	if err != nil {
+		return
+	}
+
+	schema = map[string]interface{}{
+		"type":    "string",
+		"pattern": expr.String(),
+	}
+	if example, e := (generator{}).generateFilename(s); e == nil {
+		schema["example"] = example
+	}
+	if len(s.Filename.Comments) > 0 {
+		schema["description"] = strings.Join(s.Filename.Comments, " ")
+	}
+	return
+}
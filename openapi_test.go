@@ -0,0 +1,40 @@
+package synta
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOpenAPISchema(t *testing.T) {
+	synta := MustSynta(`name = [a-z]+
+year = [0-9]{4}
+ext = pdf
+; the notes of a lesson
+; in pdf format
+> name(-year)?.ext`)
+
+	schema, err := synta.OpenAPISchema()
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"type":        "string",
+		"pattern":     `^(?:[a-z]+)(?:-(?:[0-9]{4}))?\.(?:pdf)$`,
+		"example":     "a-0000.pdf",
+		"description": "the notes of a lesson in pdf format",
+	}, schema)
+
+	_, err = json.Marshal(schema)
+	assert.Nil(t, err)
+}
+
+func TestOpenAPISchemaWithoutComments(t *testing.T) {
+	synta := MustSynta(`name = [a-z]+
+ext = pdf
+> name.ext`)
+
+	schema, err := synta.OpenAPISchema()
+	assert.Nil(t, err)
+	assert.NotContains(t, schema, "description")
+	assert.Equal(t, "a.pdf", schema["example"])
+}
@@ -0,0 +1,73 @@
+package synta
+
+import (
+	"strings"
+)
+
+// Options tweaks the behaviour of the parser. The zero value parses a file
+// the same way ParseSynta does.
+type Options struct {
+	// LazyCompile defers the compilation of each definition's regexp until
+	// Definition.Compiled is called. Invalid regexps are then only reported
+	// when compiled.
+	LazyCompile bool
+	// Builtins provides the builtin definitions for the identifiers which are
+	// referenced by the filename but not defined in the file
+	Builtins bool
+	// CommentPrefixes are the prefixes introducing a comment line. When empty,
+	// only ";" is recognized; add it to the list to keep supporting it.
+	CommentPrefixes []string
+	// MaxLineLength is the maximum length in bytes of a line. Zero means
+	// DefaultMaxLineLength, while a negative value disables the limit.
+	MaxLineLength int
+	// LowercaseInput makes the spec lowercase filenames before matching them,
+	// so that lowercase definitions accept mixed-case input and extracted
+	// values are lowercase. Unlike the (?i) flag, which makes a definition
+	// case-insensitive, it applies to the whole filename and changes the
+	// values: a definition only accepting uppercase letters never matches.
+	LowercaseInput bool
+	// MatchStrategy chooses the interpretation returned by Extract when the
+	// optional segments of the spec can match a filename in more than one way
+	MatchStrategy MatchStrategy
+	// Strict checks the shape of every line before parsing the file, and
+	// rejects the lines which are not clearly a comment, a directive, a
+	// filename declaration starting with "> " or a definition written
+	// `<id> = <regexp>`, telling which of them was expected
+	Strict bool
+	// MultipleFilenames allows more than one filename declaration: the first
+	// one is the Filename of the spec, while the others are its Alternatives
+	MultipleFilenames bool
+	// DefinitionsOnly parses a file of shared definitions, which declares no
+	// filename and no directive, meant to be combined with other specs by
+	// Merge
+	DefinitionsOnly bool
+}
+
+// DefaultMaxLineLength is the line length limit used when none is given
+const DefaultMaxLineLength = 64 * 1024
+
+func (opts Options) maxLineLength() int {
+	if opts.MaxLineLength == 0 {
+		return DefaultMaxLineLength
+	}
+	return opts.MaxLineLength
+}
+
+// commentPrefixes returns the prefixes introducing a comment
+func (opts Options) commentPrefixes() []string {
+	if len(opts.CommentPrefixes) == 0 {
+		return []string{";"}
+	}
+	return opts.CommentPrefixes
+}
+
+// comment returns the text of a comment line without its prefix, and whether
+// the line is a comment at all
+func (opts Options) comment(line string) (text string, ok bool) {
+	for _, prefix := range opts.commentPrefixes() {
+		if strings.HasPrefix(line, prefix) {
+			return strings.TrimSpace(line[len(prefix):]), true
+		}
+	}
+	return "", false
+}
@@ -4,78 +4,458 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"unicode"
 )
 
 // ParseSynta attempts to parse a file's contents into a Synta internal
 // representation. If an error is encountered the parsing is aborted and the
-// error returned
+// error returned. The filename line may appear anywhere in the file: the
+// order of definitions relative to it does not matter.
 func ParseSynta(contents string) (s Synta, err error) {
-	lines := strings.Split(contents, "\n")
-	// remove blank lines
-	for i := 0; i < len(lines); i++ {
+	return ParseSyntaWithOptions(contents, Options{})
+}
+
+// ParseSyntaWithOptions works like ParseSynta, but allows tweaking the
+// behaviour of the parser through the given options
+func ParseSyntaWithOptions(contents string, opts Options) (s Synta, err error) {
+	lines := splitLines(contents)
+	if limit := opts.maxLineLength(); limit > 0 {
+		for i, line := range lines {
+			if len(line) > limit {
+				err = SyntaError{i + 1, limit, fmt.Sprintf("line %d is %d bytes long, exceeding the maximum of %d", i+1, len(line), limit), TokenError}
+				return
+			}
+		}
+	}
+	// remove blank lines, remembering where the others come from
+	origins := []origin{}
+	for i, j := 0, 0; i < len(lines); i, j = i+1, j+1 {
+		indent := len(lines[i]) - len(strings.TrimLeft(lines[i], " \t"))
 		lines[i] = strings.TrimSpace(lines[i])
 		if lines[i] == "" {
 			lines = append(lines[:i], lines[i+1:]...)
+			i--
+		} else {
+			origins = append(origins, origin{j + 1, indent})
+		}
+	}
+	if len(lines) == 0 {
+		err = errors.New("Empty file provided")
+		return
+	}
+	// join the definitions continued on the following lines, which are
+	// located at the line they start from
+	for i := 0; i < len(lines); i++ {
+		for isContinued(lines[i], opts) {
+			if i+1 == len(lines) {
+				err = origins[i].locate(SyntaError{Pos: len(lines[i]) - 1, Msg: "dangling `\\` continuation at the end of the file", Token: TokenPattern})
+				return
+			}
+			lines[i] = lines[i][:len(lines[i])-1] + lines[i+1]
+			lines = append(lines[:i+1], lines[i+2:]...)
+			origins = append(origins[:i+1], origins[i+2:]...)
+		}
+	}
+	if opts.Strict {
+		for i, line := range lines {
+			if err = checkLineShape(line, opts); err != nil {
+				err = origins[i].locate(err)
+				return
+			}
 		}
 	}
 
 	var (
-		consumed        = 0
-		id              = Identifier("")
-		def             = Definition{}
-		definitionLines = []string{}
-		filenameLine    = ""
+		consumed          = 0
+		id                = Identifier("")
+		def               = Definition{}
+		definitionLines   = []string{}
+		definitionOrigins = []origin{}
+		filenameLines     = []string{}
+		filenameOrigins   = []origin{}
+		filenameComments  = [][]string{}
+		definitionsBefore = []int{}
 	)
-	if len(lines) > 1 {
-		definitionLines = lines[:len(lines)-1]
-		filenameLine = lines[len(lines)-1]
-	} else if len(lines) == 1 {
-		err = errors.New("Missing either the filename or defintions")
-	} else {
-		err = errors.New("Empty file provided")
+	declaration := map[int]bool{}
+	for i, line := range lines {
+		if line[0] != '>' {
+			continue
+		}
+		if opts.DefinitionsOnly {
+			err = origins[i].locate(SyntaError{Msg: "a definitions file cannot declare a filename", Token: TokenFilename})
+			return
+		}
+		if len(filenameLines) > 0 && !opts.MultipleFilenames {
+			err = origins[i].locate(SyntaError{Msg: "multiple filename declarations found", Token: TokenFilename})
+			return
+		}
+		filenameLines = append(filenameLines, line)
+		filenameOrigins = append(filenameOrigins, origins[i])
+		// the comments right above the filename describe the filename itself
+		var comments []string
+		start := i
+		for start > 0 && !declaration[start-1] {
+			comment, isComment := opts.comment(lines[start-1])
+			if !isComment {
+				break
+			}
+			comments = append([]string{comment}, comments...)
+			start--
+		}
+		filenameComments = append(filenameComments, comments)
+		for j := start; j <= i; j++ {
+			declaration[j] = true
+		}
+		definitions := 0
+		for _, before := range lines[:start] {
+			if _, isComment := opts.comment(before); !isComment && before[0] != '!' && before[0] != '>' {
+				definitions++
+			}
+		}
+		definitionsBefore = append(definitionsBefore, definitions)
+	}
+	if len(filenameLines) == 0 && !opts.DefinitionsOnly {
+		err = errors.New("Missing the filename")
 		return
 	}
+	for i := range lines {
+		if !declaration[i] {
+			definitionLines = append(definitionLines, lines[i])
+			definitionOrigins = append(definitionOrigins, origins[i])
+		}
+	}
+
+	// directives may appear anywhere in the file and are only parsed
+	// once the filename is known, as they refer to its segments
+	directiveLines := []string{}
+	directiveOrigins := []origin{}
+	for i := 0; i < len(definitionLines); i++ {
+		if definitionLines[i][0] == '!' {
+			if opts.DefinitionsOnly {
+				err = definitionOrigins[i].locate(SyntaError{Msg: "a definitions file cannot contain directives", Token: TokenDirective})
+				return
+			}
+			directiveLines = append(directiveLines, definitionLines[i])
+			directiveOrigins = append(directiveOrigins, definitionOrigins[i])
+			definitionLines = append(definitionLines[:i], definitionLines[i+1:]...)
+			definitionOrigins = append(definitionOrigins[:i], definitionOrigins[i+1:]...)
+			i--
+		}
+	}
 
+	s.LowercaseInput = opts.LowercaseInput
+	s.MatchStrategy = opts.MatchStrategy
 	s.Definitions = map[Identifier]Definition{}
 	for len(definitionLines) > 0 {
-		consumed, id, def, err = parseFirstDefinition(definitionLines)
+		consumed, id, def, err = parseFirstDefinition(definitionLines, opts)
 		definitionLines = definitionLines[consumed:]
+		at := definitionOrigins[consumed-1]
+		definitionOrigins = definitionOrigins[consumed:]
 		if err != nil {
+			err = at.locate(err)
 			return
 		}
 
 		if _, ok := s.Definitions[id]; ok {
-			err = fmt.Errorf("defintion for `%s` is provided twice", id)
+			first := s.Nodes[slices.IndexFunc(s.Nodes, func(node Node) bool { return node.Identifier == id })]
+			err = at.locate(SyntaError{Msg: fmt.Sprintf("defintion for `%s` is provided twice, at lines %d and %d", id, first.Line, at.line), Token: TokenIdentifier})
 			return
 		}
 		s.Definitions[id] = def
+		definition := def
+		s.Nodes = append(s.Nodes, Node{Type: NodeTypeDefinition, Identifier: id, Definition: &definition, Line: at.line})
+	}
+
+	if opts.DefinitionsOnly {
+		return
+	}
+
+	for i, line := range filenameLines {
+		filename, e := parseFilenameDeclaration(line, opts)
+		if e != nil {
+			err = filenameOrigins[i].locate(e)
+			return
+		}
+		filename.Comments = filenameComments[i]
+		if i == 0 {
+			s.Filename = filename
+		} else {
+			s.Alternatives = append(s.Alternatives, filename)
+		}
+	}
+	// each filename node is inserted after the definitions declared above it
+	// and the filename nodes inserted so far
+	for i, f := range s.filenames() {
+		filename := f
+		s.Nodes = slices.Insert(s.Nodes, definitionsBefore[i]+i, Node{Type: NodeTypeFilename, Filename: &filename, Line: filenameOrigins[i].line})
+	}
+
+	if opts.Builtins {
+		provideBuiltins(&s, opts)
+	}
+
+	for i, f := range s.filenames() {
+		alternative := s
+		alternative.Filename, alternative.Alternatives = f, nil
+		if missing := alternative.MissingDefinitions(); len(missing) > 0 {
+			msg := fmt.Sprintf("missing definition for `%s`", missing[0])
+			err = filenameOrigins[i].locate(SyntaError{Pos: tokenPos(filenameLines[i], TokenIdentifier, string(missing[0])), Msg: msg, Token: TokenIdentifier})
+			return
+		}
+	}
+
+	for i, f := range s.filenames() {
+		for _, id := range getBackreferences(f.Segments) {
+			if !slices.Contains(getReferencedIdentifiers(f.Segments), id) {
+				msg := fmt.Sprintf("`=%s` references `%s`, which is not part of the filename", id, id)
+				err = filenameOrigins[i].locate(SyntaError{Pos: tokenPos(filenameLines[i], TokenBackreference, "="), Msg: msg, Token: TokenBackreference})
+				return
+			}
+			c := Constraint{Kind: ConstraintEqual, Subject: id}
+			if !slices.Contains(s.Constraints, c) {
+				s.Constraints = append(s.Constraints, c)
+			}
+		}
+	}
 
+	for i, line := range directiveLines {
+		if err = parseDirective(&s, line); err != nil {
+			err = directiveOrigins[i].locate(SyntaError{Msg: err.Error(), Token: TokenDirective})
+			return
+		}
 	}
 
-	s.Filename.Segments, s.Filename.Extension, err = parseFilename(filenameLine)
+	return
+}
+
+// ValidateSyntax checks the structure of a spec, such as its filename and the
+// presence of the required definitions, without compiling any regexp. It is
+// meant for quick, repeated checks: patterns are treated as opaque strings and
+// their validity must be checked separately, for example with ParseSynta.
+func ValidateSyntax(contents string) (err error) {
+	_, err = ParseSyntaWithOptions(contents, Options{LazyCompile: true})
+	return
+}
+
+// ParseSyntaWithOverrides works like ParseSynta, then replaces the regexp of
+// the overridden definitions, keeping their comments and length bounds. It
+// fails when an overridden identifier is not defined or its new regexp is
+// invalid.
+func ParseSyntaWithOverrides(contents string, overrides map[Identifier]string) (s Synta, err error) {
+	s, err = ParseSynta(contents)
 	if err != nil {
 		return
 	}
-	requiredIdentifiers := getRequiredIdentifiers(s.Filename.Segments)
-	requiredIdentifiers = append(requiredIdentifiers, s.Filename.Extension)
-	for _, id := range requiredIdentifiers {
-		if _, ok := s.Definitions[id]; !ok {
-			err = fmt.Errorf("missing definition for `%s`", id)
+
+	for id, pattern := range overrides {
+		def, ok := s.Definitions[id]
+		if !ok {
+			err = fmt.Errorf("cannot override `%s`, which is not defined", id)
 			return
 		}
+		def.Pattern = pattern
+		if def.Regexp, err = regexp.Compile(pattern); err != nil {
+			err = fmt.Errorf("invalid override for `%s`: %v", id, err)
+			return
+		}
+		s.Definitions[id] = def
+		for i, node := range s.Nodes {
+			if node.Type == NodeTypeDefinition && node.Identifier == id {
+				definition := def
+				s.Nodes[i].Definition = &definition
+			}
+		}
+	}
+	return
+}
+
+// isContinued tells whether the definition on the line continues on the next
+// one, as its last character is a `\` which does not escape another one
+func isContinued(line string, opts Options) bool {
+	if _, ok := opts.comment(line); ok || line[0] == '>' || line[0] == '!' {
+		return false
 	}
+	return endsWithContinuation(line)
+}
+
+// endsWithContinuation tells whether the line ends with a `\` which does not
+// escape another one
+func endsWithContinuation(line string) bool {
+	trailing := len(line) - len(strings.TrimRight(line, `\`))
+	return trailing%2 == 1
+}
 
+// parseFilenameDeclaration parses a filename declaration, along with the
+// comment trailing it
+func parseFilenameDeclaration(line string, opts Options) (filename Filename, err error) {
+	line, extensionComment, at := splitTrailingComment(line, opts)
+	if at >= 0 {
+		filename.ExtensionComments = []string{extensionComment}
+	}
+	var exts []Identifier
+	if filename.Segments, exts, err = parseFilename(line); err != nil {
+		return
+	}
+	if len(exts) > 0 {
+		filename.Extension = exts[0]
+	}
+	if len(exts) > 1 {
+		filename.Extensions = exts
+	}
+	if !opts.LazyCompile {
+		err = compileInlines(filename.Segments)
+	}
 	return
 }
 
+// checkLineShape checks that the trimmed line is a comment, a directive, a
+// filename declaration or a definition, as required by Options.Strict
+func checkLineShape(line string, opts Options) error {
+	if _, ok := opts.comment(line); ok || line[0] == '!' {
+		return nil
+	}
+	if line[0] == '>' {
+		if !strings.HasPrefix(line, "> ") || strings.TrimSpace(line[1:]) == "" {
+			return SyntaError{Pos: 1, Msg: "expected `> ` followed by the filename", Token: TokenFilename}
+		}
+		return nil
+	}
+
+	id, pattern, found := strings.Cut(line, " = ")
+	if !found {
+		return SyntaError{Msg: "expected a comment, a directive, `> <filename>` or `<id> = <regexp>`", Token: TokenError}
+	}
+	if i := invalidIdentifierChar(id); i >= 0 || id == "" {
+		return SyntaError{Pos: max(i, 0), Msg: fmt.Sprintf("invalid identifier `%s`, expected `<id> = <regexp>`", id), Token: TokenIdentifier}
+	}
+	if strings.TrimSpace(pattern) == "" {
+		return SyntaError{Pos: len(id) + 3, Msg: fmt.Sprintf("missing the regexp of `%s`", id), Token: TokenPattern}
+	}
+	return nil
+}
+
 func getRequiredIdentifiers(segments []Segment) (requiredIdentifiers []Identifier) {
-	for _, seg := range segments {
-		if seg.Kind == SegmentTypeOptional {
-			requiredIdentifiers = append(requiredIdentifiers, getRequiredIdentifiers(seg.Subsegments)...)
-		} else {
+	WalkSegments(segments, func(seg Segment) bool {
+		if seg.Kind == SegmentTypeIdentifier || seg.Kind == SegmentTypeBackreference {
 			requiredIdentifiers = append(requiredIdentifiers, *seg.Value)
 		}
+		return true
+	})
+	return
+}
+
+// getReferencedIdentifiers returns the identifiers of the segments which are
+// not backreferences, including those inside optionals
+func getReferencedIdentifiers(segments []Segment) (identifiers []Identifier) {
+	WalkSegments(segments, func(seg Segment) bool {
+		if seg.Kind == SegmentTypeIdentifier {
+			identifiers = append(identifiers, *seg.Value)
+		}
+		return true
+	})
+	return
+}
+
+// getBackreferences returns the identifiers referenced by backreference
+// segments, including those inside optionals
+func getBackreferences(segments []Segment) (identifiers []Identifier) {
+	WalkSegments(segments, func(seg Segment) bool {
+		if seg.Kind == SegmentTypeBackreference {
+			identifiers = append(identifiers, *seg.Value)
+		}
+		return true
+	})
+	return
+}
+
+// tokenPos returns the position of the first token of the given type and
+// value within the trimmed line, or zero if there is none
+func tokenPos(line string, typ TokenType, value string) int {
+	l := NewLexer(line, Options{})
+	l.Recover = true
+	for {
+		tok, err := l.NextToken()
+		if err != nil || tok.Type == TokenEOF {
+			return 0
+		}
+		if tok.Type == typ && tok.Value == value {
+			return tok.Pos
+		}
+	}
+}
+
+// splitTrailingComment separates the comment trailing a filename declaration,
+// introduced by a space and a comment prefix, from the declaration itself.
+// Inline patterns are skipped, as they may contain such characters. The
+// offset of the comment prefix is returned, or -1 without a comment.
+func splitTrailingComment(line string, opts Options) (declaration string, comment string, at int) {
+	depth := 0
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case ' ':
+			if depth != 0 || i < 2 {
+				continue
+			}
+			if comment, ok := opts.comment(line[i+1:]); ok {
+				return strings.TrimRight(line[:i], " "), comment, i + 1
+			}
+		}
+	}
+	return line, "", -1
+}
+
+// splitPatternComment separates the comment trailing the pattern of a
+// definition, introduced by a comment prefix surrounded by spaces, from the
+// pattern itself. Character classes and escaped characters are skipped, as
+// they may contain such characters. The offset of the comment prefix is
+// returned, or -1 without a comment.
+func splitPatternComment(pattern string, opts Options) (expr string, comment string, at int) {
+	class := false
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+		case '[':
+			class = true
+		case ']':
+			class = false
+		case ' ':
+			if class {
+				continue
+			}
+			rest := pattern[i+1:]
+			for _, prefix := range opts.commentPrefixes() {
+				if strings.HasPrefix(rest, prefix) && (len(rest) == len(prefix) || rest[len(prefix)] == ' ') {
+					return strings.TrimRight(pattern[:i], " "), strings.TrimSpace(rest[len(prefix):]), i + 1
+				}
+			}
+		}
+	}
+	return pattern, "", -1
+}
+
+// compileInlines compiles the patterns of the inline segments
+func compileInlines(segments []Segment) (err error) {
+	for _, seg := range segments {
+		switch seg.Kind {
+		case SegmentTypeInline:
+			if seg.Inline.Regexp, err = seg.Inline.Compiled(); err != nil {
+				return SyntaError{Msg: fmt.Sprintf("invalid inline pattern `{%s}`: %v", seg.Inline.Pattern, err), Token: TokenInline}
+			}
+		case SegmentTypeOptional, SegmentTypeAlternation, SegmentTypeRepeat:
+			if err = compileInlines(seg.Subsegments); err != nil {
+				return
+			}
+		}
 	}
 	return
 }
@@ -93,27 +473,73 @@ func MustSynta(contents string) Synta {
 // All the lines from start to the defintion must be comments. If the defintion
 // identifier is not valid, we return an error, otherwise, the definition index,
 // the definition identifier and the definition itself are returned.
-func parseFirstDefinition(lines []string) (consumed int, id Identifier, def Definition, err error) {
+func parseFirstDefinition(lines []string, opts Options) (consumed int, id Identifier, def Definition, err error) {
 	for _, line := range lines {
 		consumed++
-		if line[0] == ';' {
-			def.Comments = append(def.Comments, strings.TrimSpace(line[1:]))
-		} else {
-			parsed_line := strings.Split(line, " = ")
-			raw_id, expr := parsed_line[0], parsed_line[1]
-			if !IdentifierRegexp.Match([]byte(raw_id)) {
-				err = fmt.Errorf("Invalid identifier: %s", raw_id)
-				return
-			}
-			id = Identifier(raw_id)
-			def.Regexp, err = regexp.Compile(expr)
+		l := &Lexer{Recover: true, opts: opts, line: 1}
+		tokens, _ := l.lexLine(line)
+		if tokens[0].Type == TokenComment {
+			def.Comments = append(def.Comments, tokens[0].Value)
+			continue
+		}
+		// a definition is lexed as its identifier, the assignment and the
+		// pattern, which may be followed by a comment
+		if len(tokens) < 3 {
+			err = SyntaError{Pos: len(line), Msg: fmt.Sprintf("Invalid definition, expected `<id> = <regexp>`: %s", line), Token: TokenError}
+			return
+		}
+		raw_id, pattern := tokens[0].Value, tokens[2]
+		if len(tokens) > 3 {
+			def.Comments = append(def.Comments, tokens[3].Value)
+		}
+		if i := invalidIdentifierChar(raw_id); i >= 0 || raw_id == "" {
+			err = SyntaError{Pos: max(i, 0), Msg: fmt.Sprintf("Invalid identifier: %s", raw_id), Token: TokenIdentifier}
+			return
+		}
+		id = Identifier(raw_id)
+		expr := pattern.Value
+		if expr, def.MinLen, def.MaxLen, err = parseLength(expr); err != nil {
+			err = SyntaError{Pos: pattern.Pos, Msg: err.Error(), Token: TokenPattern}
 			return
 		}
+		def.Pattern = expr
+		if opts.LazyCompile {
+			def.lazy = &lazyRegexp{}
+		} else if def.Regexp, err = regexp.Compile(expr); err != nil {
+			err = SyntaError{Pos: pattern.Pos, Msg: err.Error(), Token: TokenPattern}
+		}
+		return
 	}
 	err = errors.New("No next definition")
 	return
 }
 
+// lengthAnnotation matches the optional `len(min,max)` annotation following
+// the regexp of a definition
+var lengthAnnotation = regexp.MustCompile(` +len\((\d*),(\d*)\)$`)
+
+// parseLength strips the length annotation from the expression of a
+// definition, if any, returning the bare regexp and the bounds
+func parseLength(expr string) (pattern string, minLen, maxLen int, err error) {
+	pattern = expr
+	match := lengthAnnotation.FindStringSubmatchIndex(expr)
+	if match == nil {
+		return
+	}
+
+	pattern = expr[:match[0]]
+	if match[3] > match[2] {
+		minLen, _ = strconv.Atoi(expr[match[2]:match[3]])
+	}
+	if match[5] > match[4] {
+		maxLen, _ = strconv.Atoi(expr[match[4]:match[5]])
+	}
+	if maxLen > 0 && minLen > maxLen {
+		err = fmt.Errorf("Invalid length, the minimum %d exceeds the maximum %d", minLen, maxLen)
+	}
+	return
+}
+
 type State uint8
 
 const (
@@ -126,14 +552,33 @@ const (
 	State6
 	State7
 	State8
+	State9
+	State10
+	State11
+	State12
+	State13
+	State14
+	State15
+	State16
+	State17
+	State18
+	State19
+	State20
+	State21
+	State22
+	State23
+	State24
+	State25
 )
 
-func isLetter(c byte) bool {
-	return c >= 'a' && c <= 'z'
+// isLetter tells whether the rune may be part of an identifier, which is made
+// of Unicode letters of any case. Digits and separators are excluded.
+func isLetter(c rune) bool {
+	return unicode.IsLetter(c)
 }
 
-func concat(seg *Segment, c byte) {
-	val := Identifier(string(*seg.Value) + string(c))
+func concat(seg *Segment, value string) {
+	val := Identifier(string(*seg.Value) + value)
 	seg.Value = &val
 }
 
@@ -141,6 +586,82 @@ func clear(seg *Segment) {
 	emptyValue := Identifier("")
 	seg.Value = &emptyValue
 	seg.Kind = SegmentTypeIdentifier
+	seg.Inline = nil
+}
+
+// readInlineToken reads the pattern of an inline token into the segment
+func readInlineToken(tok Token, seg *Segment) {
+	seg.Kind = SegmentTypeInline
+	seg.Value = nil
+	seg.Inline = &Definition{Pattern: tok.Value}
+}
+
+// readLiteralToken reads the separators of a literal token into the segment
+func readLiteralToken(tok Token, seg *Segment) {
+	seg.Kind = SegmentTypeLiteral
+	value := Identifier(tok.Value)
+	seg.Value = &value
+	seg.Inline = nil
+}
+
+// invalidToken describes why the lexer rejected the token: inline patterns and
+// literals tell what is wrong with them, while other characters are merely
+// unexpected
+func invalidToken(tok Token) (err error) {
+	switch tok.Value[0] {
+	case '{':
+		_, err = inlineEnd(tok.Value, 0)
+	case '\\':
+		_, err = readLiteral(tok.Value, 0, &Segment{})
+	}
+	return
+}
+
+// readLiteral reads the escaped separators starting at the given column, such
+// as `\-\.`, into a literal segment. The column of the last character read
+// is returned.
+func readLiteral(line string, col int, seg *Segment) (end int, err error) {
+	text := ""
+	for end = col; end < len(line) && line[end] == '\\'; end += 2 {
+		if end+1 == len(line) || line[end+1] != '-' && line[end+1] != '.' {
+			err = errors.New("Expected a - or a . after a \\")
+			return
+		}
+		text += line[end+1 : end+2]
+	}
+
+	seg.Kind = SegmentTypeLiteral
+	value := Identifier(text)
+	seg.Value = &value
+	seg.Inline = nil
+	return end - 1, nil
+}
+
+// inlineEnd finds the closing brace of the inline segment whose opening
+// brace is at the given column. Braces within the pattern must be balanced
+// or escaped.
+func inlineEnd(line string, col int) (end int, err error) {
+	depth := 0
+	for end = col; end < len(line); end++ {
+		switch line[end] {
+		case '\\':
+			end++
+		case '{':
+			depth++
+		case '}':
+			depth--
+		}
+		if depth == 0 {
+			break
+		}
+	}
+	if end >= len(line) {
+		return len(line) - 1, errors.New("Unterminated inline pattern, expected a }")
+	}
+	if end == col+1 {
+		return end, errors.New("Empty inline pattern")
+	}
+	return
 }
 
 func push(segments []Segment, seg *Segment, depth int) (updatedSegments []Segment) {
@@ -158,9 +679,45 @@ func push(segments []Segment, seg *Segment, depth int) (updatedSegments []Segmen
 	return
 }
 
+// lastGroup returns the optional or alternation segment most recently opened
+// at the given depth, which must be positive
+func lastGroup(segments []Segment, depth int) *Segment {
+	for i := 0; i < depth-1; i++ {
+		segments = segments[len(segments)-1].Subsegments
+	}
+	return &segments[len(segments)-1]
+}
+
+// branchOut turns the single segment of the optional most recently opened at
+// the given depth into the first branch of an alternation nested in it, as in
+// `(-a|-b)?`, which stands for `(-(a|b))?`
+func branchOut(segments []Segment, depth int) (updatedSegments []Segment, err error) {
+	optional := lastGroup(segments, depth)
+	if optional.Kind != SegmentTypeOptional || len(optional.Subsegments) != 1 {
+		err = errors.New("Only an optional segment made of a single identifier or inline segment can branch")
+		return
+	}
+	branch := optional.Subsegments[0]
+	if branch.Kind != SegmentTypeIdentifier && branch.Kind != SegmentTypeInline {
+		err = errors.New("Only an identifier or an inline segment can be a branch")
+		return
+	}
+	optional.Subsegments = []Segment{{SegmentTypeAlternation, nil, []Segment{branch}, nil}}
+	return segments, nil
+}
+
+// closeAlternation ensures the alternation most recently opened at the given
+// depth has at least two branches
+func closeAlternation(segments []Segment, depth int) (err error) {
+	if len(lastGroup(segments, depth).Subsegments) < 2 {
+		err = errors.New("An alternation needs at least two branches")
+	}
+	return
+}
+
 func generateOptional(segments []Segment, depth int) (updatedSegments []Segment) {
 	backup := segments
-	newOptional := Segment{SegmentTypeOptional, nil, []Segment{}}
+	newOptional := Segment{SegmentTypeOptional, nil, []Segment{}, nil}
 
 	for i := 0; i < depth-1; i++ {
 		segments = segments[len(segments)-1].Subsegments
@@ -175,10 +732,11 @@ func generateOptional(segments []Segment, depth int) (updatedSegments []Segment)
 }
 
 // parseFilename checks if the line starts with "> ", or errors otherwise.
-// Then, it parses a list of segments from the line using a DFA. If an invalid
-// char is found, an error is returned, otherwise the result is the list of
-// prased defintions.
-func parseFilename(line string) (def []Segment, ext Identifier, err error) {
+// Then, it parses a list of segments from the tokens of the line using a DFA.
+// If an invalid token is found, an error is returned, otherwise the result is
+// the list of prased defintions. A filename ending with a `$` rather than an
+// extension has no extension, so none is returned.
+func parseFilename(line string) (def []Segment, exts []Identifier, err error) {
 	if len(line) < 2 || line[:2] != "> " {
 		err = errors.New("Not a Filename")
 		return
@@ -189,122 +747,366 @@ func parseFilename(line string) (def []Segment, ext Identifier, err error) {
 	seg := Segment{}
 	clear(&seg)
 
+	// the characters the lexer cannot recognize become error tokens, which
+	// no state accepts
+	l := &Lexer{Recover: true, line: 1}
+	tokens, _ := l.lexFilename(line, 0)
 	col := 0
-	for col = 0; err == nil && col < len(line); col++ {
-		c := line[col]
+	for i := 0; err == nil && i < len(tokens); i++ {
+		tok := tokens[i]
+		col = tok.Pos + 1
+		if tok.Type == TokenError {
+			if err = invalidToken(tok); err != nil {
+				break
+			}
+		}
 		switch state {
 		case State0:
-			if isLetter(c) {
-				concat(&seg, c)
+			if tok.Type == TokenIdentifier {
+				concat(&seg, tok.Value)
 				state = State1
-			} else if c == '(' {
+			} else if tok.Type == TokenOpen {
 				def = generateOptional(def, depth)
 				depth++
-				state = State2
+				state = State13
+			} else if tok.Type == TokenBackreference {
+				seg.Kind = SegmentTypeBackreference
+				state = State9
+			} else if tok.Type == TokenInline {
+				readInlineToken(tok, &seg)
+				def = push(def, &seg, depth)
+				state = State11
+			} else if tok.Type == TokenLiteral && tok.Pos == 0 {
+				readLiteralToken(tok, &seg)
+				def = push(def, &seg, depth)
+				state = State20
 			} else {
-				err = errors.New("Expected either a char or a (")
+				err = errors.New("Expected either a char, or a ( or a = or a {")
 			}
 		case State1:
-			if isLetter(c) {
-				concat(&seg, c)
-			} else if c == '-' {
+			if tok.Type == TokenDash {
 				def = push(def, &seg, depth)
 				state = State0
-			} else if c == '(' {
+			} else if tok.Type == TokenOpen {
 				def = push(def, &seg, depth)
 				def = generateOptional(def, depth)
 				depth++
 				state = State2
-			} else if c == '.' {
+			} else if tok.Type == TokenDot {
 				if depth == 0 {
 					def = push(def, &seg, depth)
 					state = State7
 				} else {
 					err = errors.New("depth is not 0, you must close the optional segment")
 				}
+			} else if tok.Type == TokenLiteral && depth == 0 {
+				def = push(def, &seg, depth)
+				readLiteralToken(tok, &seg)
+				def = push(def, &seg, depth)
+				state = State20
+			} else if tok.Type == TokenEnd && depth == 0 {
+				def = push(def, &seg, depth)
+				state = State21
 			} else {
 				err = errors.New("expected either a char, or a -, or a ( or a .")
 			}
 		case State2:
-			if c == '-' {
+			if tok.Type == TokenDash {
 				state = State3
 			} else {
 				err = errors.New("Expected a -")
 			}
 		case State3:
-			if isLetter(c) {
-				concat(&seg, c)
+			if tok.Type == TokenIdentifier {
+				concat(&seg, tok.Value)
 				state = State4
+			} else if tok.Type == TokenBackreference {
+				seg.Kind = SegmentTypeBackreference
+				state = State10
+			} else if tok.Type == TokenInline {
+				readInlineToken(tok, &seg)
+				def = push(def, &seg, depth)
+				state = State12
+			} else if tok.Type == TokenOpen {
+				def = generateOptional(def, depth)
+				depth++
+				lastGroup(def, depth).Kind = SegmentTypeAlternation
+				state = State14
 			} else {
-				err = errors.New("Expected a char")
+				err = errors.New("Expected either a char or a = or a { or a (")
 			}
 		case State4:
-			if isLetter(c) {
-				concat(&seg, c)
-			} else if c == ')' {
+			if tok.Type == TokenClose {
 				def = push(def, &seg, depth)
 				depth--
 				state = State5
-			} else if c == '(' {
+			} else if tok.Type == TokenOpen {
 				def = push(def, &seg, depth)
 				def = generateOptional(def, depth)
 				depth++
 				state = State2
+			} else if tok.Type == TokenPipe {
+				def = push(def, &seg, depth)
+				def, err = branchOut(def, depth)
+				depth++
+				state = State22
 			} else {
-				err = errors.New("Expected a char, or a ( or a )")
+				err = errors.New("Expected a char, or a ( or a ) or a |")
 			}
 		case State5:
-			if c == '?' {
+			if tok.Type == TokenQuestion {
+				state = State6
+			} else if tok.Type == TokenPlus {
+				lastGroup(def, depth+1).Kind = SegmentTypeRepeat
 				state = State6
 			} else {
-				err = errors.New("Expected a ?")
+				err = errors.New("Expected a ? or a +")
 			}
 		case State6:
-			switch c {
-			case '-':
+			switch tok.Type {
+			case TokenDash:
 				state = State0
-			case '.':
+			case TokenDot:
 				if depth == 0 {
 					state = State7
 				} else {
 					err = errors.New("Depth is not 0, you must close the optional segment")
 				}
-			case '(':
+			case TokenOpen:
 				def = generateOptional(def, depth)
 				depth++
 				state = State2
-			case ')':
+			case TokenClose:
 				depth--
 				state = State5
+			case TokenLiteral:
+				if depth == 0 {
+					readLiteralToken(tok, &seg)
+					def = push(def, &seg, depth)
+					state = State20
+				} else {
+					err = errors.New("Depth is not 0, you must close the optional segment")
+				}
+			case TokenEnd:
+				if depth == 0 {
+					state = State21
+				} else {
+					err = errors.New("Depth is not 0, you must close the optional segment")
+				}
 			default:
 				err = errors.New("Expected either a - or a . or a ( or a )")
 			}
 		case State7:
-			if isLetter(c) {
-				concat(&seg, c)
+			if tok.Type == TokenIdentifier {
+				concat(&seg, tok.Value)
 				state = State8
+			} else if tok.Type == TokenOpen {
+				state = State17
+			} else {
+				err = errors.New("Expected a char or a (")
+			}
+		case State9:
+			if tok.Type == TokenIdentifier {
+				concat(&seg, tok.Value)
+				state = State1
+			} else {
+				err = errors.New("Expected a char")
+			}
+		case State10:
+			if tok.Type == TokenIdentifier {
+				concat(&seg, tok.Value)
+				state = State4
 			} else {
 				err = errors.New("Expected a char")
 			}
-		case State8:
-			if isLetter(c) {
-				concat(&seg, c)
+		case State11:
+			if tok.Type == TokenDash {
+				state = State0
+			} else if tok.Type == TokenOpen {
+				def = generateOptional(def, depth)
+				depth++
+				state = State2
+			} else if tok.Type == TokenDot {
+				if depth == 0 {
+					state = State7
+				} else {
+					err = errors.New("depth is not 0, you must close the optional segment")
+				}
+			} else if tok.Type == TokenLiteral {
+				readLiteralToken(tok, &seg)
+				def = push(def, &seg, depth)
+				state = State20
+			} else if tok.Type == TokenEnd && depth == 0 {
+				state = State21
+			} else {
+				err = errors.New("expected either a -, or a ( or a .")
+			}
+		case State12:
+			if tok.Type == TokenClose {
+				depth--
+				state = State5
+			} else if tok.Type == TokenOpen {
+				def = generateOptional(def, depth)
+				depth++
+				state = State2
+			} else if tok.Type == TokenPipe {
+				def, err = branchOut(def, depth)
+				depth++
+				state = State22
+			} else {
+				err = errors.New("Expected a ( or a ) or a |")
+			}
+		case State13:
+			if tok.Type == TokenDash {
+				state = State3
+			} else if tok.Type == TokenIdentifier {
+				lastGroup(def, depth).Kind = SegmentTypeAlternation
+				concat(&seg, tok.Value)
+				state = State15
+			} else if tok.Type == TokenInline {
+				lastGroup(def, depth).Kind = SegmentTypeAlternation
+				readInlineToken(tok, &seg)
+				def = push(def, &seg, depth)
+				state = State16
+			} else {
+				err = errors.New("Expected either a -, or a char or a {")
+			}
+		case State14:
+			if tok.Type == TokenIdentifier {
+				concat(&seg, tok.Value)
+				state = State15
+			} else if tok.Type == TokenInline {
+				readInlineToken(tok, &seg)
+				def = push(def, &seg, depth)
+				state = State16
+			} else {
+				err = errors.New("Expected either a char or a {")
+			}
+		case State15, State16:
+			if tok.Type == TokenPipe {
+				if state == State15 {
+					def = push(def, &seg, depth)
+				}
+				state = State14
+			} else if tok.Type == TokenClose {
+				if state == State15 {
+					def = push(def, &seg, depth)
+				}
+				err = closeAlternation(def, depth)
+				depth--
+				// an alternation is followed by what follows an inline segment
+				if depth == 0 {
+					state = State11
+				} else {
+					state = State12
+				}
+			} else if state == State15 {
+				err = errors.New("Expected either a char, or a | or a )")
+			} else {
+				err = errors.New("Expected either a | or a )")
+			}
+		case State17:
+			if tok.Type == TokenIdentifier {
+				concat(&seg, tok.Value)
+				state = State18
 			} else {
 				err = errors.New("Expected a char")
 			}
+		case State18:
+			if tok.Type == TokenPipe {
+				exts = append(exts, *seg.Value)
+				clear(&seg)
+				state = State17
+			} else if tok.Type == TokenClose {
+				exts = append(exts, *seg.Value)
+				if len(exts) < 2 {
+					err = errors.New("an alternation of extensions needs at least two of them")
+				}
+				state = State19
+			} else {
+				err = errors.New("Expected either a char, or a | or a )")
+			}
+		case State8, State19, State21:
+			err = errors.New("Expected the end of the filename")
+		case State22:
+			// each branch of an alternation written directly inside an
+			// optional, as in `(-a|-b)?`, carries its own separator
+			if tok.Type == TokenDash {
+				state = State23
+			} else {
+				err = errors.New("Expected a -")
+			}
+		case State23:
+			if tok.Type == TokenIdentifier {
+				concat(&seg, tok.Value)
+				state = State24
+			} else if tok.Type == TokenInline {
+				readInlineToken(tok, &seg)
+				def = push(def, &seg, depth)
+				state = State25
+			} else {
+				err = errors.New("Expected either a char or a {")
+			}
+		case State24, State25:
+			if tok.Type == TokenPipe {
+				if state == State24 {
+					def = push(def, &seg, depth)
+				}
+				state = State22
+			} else if tok.Type == TokenClose {
+				if state == State24 {
+					def = push(def, &seg, depth)
+				}
+				// the alternation and the optional holding it close together
+				depth -= 2
+				state = State5
+			} else if state == State24 {
+				err = errors.New("Expected either a char, or a | or a )")
+			} else {
+				err = errors.New("Expected either a | or a )")
+			}
+		case State20:
+			// a literal is glued to what follows it, without a separator
+			if tok.Type == TokenIdentifier {
+				concat(&seg, tok.Value)
+				state = State1
+			} else if tok.Type == TokenBackreference {
+				seg.Kind = SegmentTypeBackreference
+				state = State9
+			} else if tok.Type == TokenInline {
+				readInlineToken(tok, &seg)
+				def = push(def, &seg, depth)
+				state = State11
+			} else if tok.Type == TokenOpen {
+				def = generateOptional(def, depth)
+				depth++
+				state = State2
+			} else if tok.Type == TokenDot {
+				state = State7
+			} else {
+				err = errors.New("Expected either a char, or a = or a { or a ( or a .")
+			}
 		}
 	}
+	if err == nil {
+		col = len(line)
+	}
 
 	// ensure that we stop on an accepting state
-	if err == nil && state != State8 {
-		err = fmt.Errorf("Stopped at a non-accepting state (was %d, expected 8)", state)
+	if err == nil && depth == 0 && (state == State1 || state == State6 || state == State11) {
+		err = errors.New("Missing the extension, end the filename with a $ if it has none")
+	} else if err == nil && state != State8 && state != State19 && state != State21 {
+		err = fmt.Errorf("Stopped at a non-accepting state (was %d, expected 8, 19 or 21)", state)
 	}
 	// handle the filename extension
-	ext = *seg.Value
+	if state == State8 {
+		exts = []Identifier{*seg.Value}
+	}
 
 	// add debug information to the error string
 	if err != nil {
-		err = fmt.Errorf("Invalid char at column %d:\n%s\n%s\n%v", col, line, strings.Repeat(" ", col-1)+"^", err)
+		msg := fmt.Sprintf("Invalid char at column %d:\n%s\n%s\n%v", col, line, strings.Repeat(" ", col-1)+"^", err)
+		err = SyntaError{Pos: col + 1, Msg: msg, Token: TokenError}
 	}
 	return
 }
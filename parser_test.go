@@ -1,6 +1,8 @@
 package synta
 
 import (
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -109,7 +111,7 @@ test = a|b
 	assert.NotEmpty(t, synta.Filename)
 	id_test := Identifier("test")
 	assert.Equal(t, synta.Filename, Filename{
-		Segments:  []Segment{{SegmentTypeIdentifier, &id_test, []Segment(nil)}, {SegmentTypeIdentifier, &id_test, []Segment(nil)}},
+		Segments:  []Segment{{SegmentTypeIdentifier, &id_test, []Segment(nil), nil}, {SegmentTypeIdentifier, &id_test, []Segment(nil), nil}},
 		Extension: Identifier("test"),
 	})
 }
@@ -131,7 +133,7 @@ test = a|b
 	assert.NotEmpty(t, synta.Filename)
 	id_test := Identifier("test")
 	assert.Equal(t, synta.Filename, Filename{
-		Segments:  []Segment{{SegmentTypeIdentifier, &id_test, []Segment(nil)}, {SegmentTypeIdentifier, &id_test, []Segment(nil)}},
+		Segments:  []Segment{{SegmentTypeIdentifier, &id_test, []Segment(nil), nil}, {SegmentTypeIdentifier, &id_test, []Segment(nil), nil}},
 		Extension: Identifier("test"),
 	})
 }
@@ -158,7 +160,7 @@ teest = a|b
 	id_test := Identifier("test")
 	id_teest := Identifier("teest")
 	assert.Equal(t, synta.Filename, Filename{
-		Segments:  []Segment{{SegmentTypeIdentifier, &id_test, []Segment(nil)}, {SegmentTypeIdentifier, &id_teest, []Segment(nil)}},
+		Segments:  []Segment{{SegmentTypeIdentifier, &id_test, []Segment(nil), nil}, {SegmentTypeIdentifier, &id_teest, []Segment(nil), nil}},
 		Extension: Identifier("teest"),
 	})
 }
@@ -184,11 +186,13 @@ test = a|b
 			{
 				SegmentTypeIdentifier,
 				&id_test,
-				[]Segment(nil)},
+				[]Segment(nil),
+				nil},
 			{
 				SegmentTypeOptional,
 				nil,
-				[]Segment{{SegmentTypeIdentifier, &id_test, []Segment(nil)}},
+				[]Segment{{SegmentTypeIdentifier, &id_test, []Segment(nil), nil}},
+				nil,
 			},
 		},
 		Extension: Identifier("test"),
@@ -219,6 +223,7 @@ test = a|b
 				SegmentTypeIdentifier,
 				&id_test,
 				[]Segment(nil),
+				nil,
 			}, {
 				Kind:  SegmentTypeOptional,
 				Value: nil,
@@ -275,3 +280,784 @@ test = a|b
 	_, err := ParseSynta(input)
 	assert.NotNil(t, err)
 }
+
+func TestFilenameString(t *testing.T) {
+	input := `test = a|b
+> test(-test(-test)?(-test)?)?-test.test`
+	synta, err := ParseSynta(input)
+	assert.Nil(t, err)
+	assert.Equal(t, "test(-test(-test)?(-test)?)?-test.test", synta.Filename.String())
+}
+
+func TestParseSyntaWithLazyCompile(t *testing.T) {
+	input := `test = a|b
+unused = +
+> test.test`
+	synta, err := ParseSyntaWithOptions(input, Options{LazyCompile: true})
+	assert.Nil(t, err)
+
+	def := synta.Definitions["test"]
+	assert.Nil(t, def.Regexp)
+	assert.Equal(t, "a|b", def.Source())
+
+	expr, err := def.Compiled()
+	assert.Nil(t, err)
+	assert.Equal(t, "a|b", expr.String())
+	// the copies in the map share the compiled regexp
+	again, err := synta.Definitions["test"].Compiled()
+	assert.Nil(t, err)
+	assert.Same(t, expr, again)
+
+	_, err = synta.Definitions["unused"].Compiled()
+	assert.NotNil(t, err)
+
+	builtin, err := ParseSyntaWithOptions("> date.ext", Options{LazyCompile: true, Builtins: true})
+	assert.Nil(t, err)
+	expr, err = builtin.Definitions["date"].Compiled()
+	assert.Nil(t, err)
+	again, err = builtin.Definitions["date"].Compiled()
+	assert.Nil(t, err)
+	assert.Same(t, expr, again)
+}
+
+func benchmarkInput() string {
+	input := ""
+	for i := 0; i < 200; i++ {
+		input += "; definition number " + strconv.Itoa(i) + "\n"
+		input += "def" + strings.Repeat("a", i) + " = ([0-9]{4}-[a-z]+|[A-Z]{2,8})\n"
+	}
+	return input + "> def.def\n"
+}
+
+func BenchmarkParseSyntaEager(b *testing.B) {
+	input := benchmarkInput()
+	for i := 0; i < b.N; i++ {
+		ParseSynta(input)
+	}
+}
+
+func BenchmarkParseSyntaLazy(b *testing.B) {
+	input := benchmarkInput()
+	for i := 0; i < b.N; i++ {
+		ParseSyntaWithOptions(input, Options{LazyCompile: true})
+	}
+}
+
+func BenchmarkValidateSyntax(b *testing.B) {
+	input := benchmarkInput()
+	for i := 0; i < b.N; i++ {
+		ValidateSyntax(input)
+	}
+}
+
+func TestValidateSyntax(t *testing.T) {
+	assert.Nil(t, ValidateSyntax("test = a|b\n> test.test"))
+	// patterns are opaque, so invalid regexps go unnoticed
+	assert.Nil(t, ValidateSyntax("test = +\n> test.test"))
+
+	assert.NotNil(t, ValidateSyntax("test = a|b\n> test-.test"))
+	assert.NotNil(t, ValidateSyntax("test = a|b\n> test-other.test"))
+	assert.NotNil(t, ValidateSyntax("test = a|b\ntest = c\n> test.test"))
+	assert.NotNil(t, ValidateSyntax("test = a|b"))
+}
+
+func TestParseSyntaNodes(t *testing.T) {
+	input := `; a comment
+test = a|b
+ext = pdf
+> test.ext`
+	synta, err := ParseSynta(input)
+	assert.Nil(t, err)
+	assert.Len(t, synta.Nodes, 3)
+
+	assert.Equal(t, NodeTypeDefinition, synta.Nodes[0].Type)
+	assert.Equal(t, Identifier("test"), synta.Nodes[0].Identifier)
+	assert.Equal(t, []string{"a comment"}, synta.Nodes[0].Definition.Comments)
+	assert.Equal(t, "a|b", synta.Nodes[0].Definition.Source())
+	assert.Nil(t, synta.Nodes[0].Filename)
+
+	assert.Equal(t, NodeTypeDefinition, synta.Nodes[1].Type)
+	assert.Equal(t, Identifier("ext"), synta.Nodes[1].Identifier)
+
+	assert.Equal(t, NodeTypeFilename, synta.Nodes[2].Type)
+	assert.Nil(t, synta.Nodes[2].Definition)
+	assert.Equal(t, synta.Filename, *synta.Nodes[2].Filename)
+}
+
+func TestParseSyntaWithFilenameFirst(t *testing.T) {
+	input := `> test-teest.teest
+
+; a test comment
+test = a|b
+
+
+teest = c|d`
+	synta, err := ParseSynta(input)
+	assert.Nil(t, err)
+
+	exp := StringDefintions{
+		"test":  {"a|b", []string{"a test comment"}},
+		"teest": {"c|d", []string(nil)},
+	}
+	checkDefinitions(t, synta.Definitions, exp)
+	assert.Equal(t, "test-teest.teest", synta.Filename.String())
+
+	assert.Len(t, synta.Nodes, 3)
+	assert.Equal(t, NodeTypeFilename, synta.Nodes[0].Type)
+	assert.Equal(t, Identifier("test"), synta.Nodes[1].Identifier)
+	assert.Equal(t, Identifier("teest"), synta.Nodes[2].Identifier)
+}
+
+func TestParseSyntaWithFilenameBetweenDefinitions(t *testing.T) {
+	input := `test = a|b
+> test-teest.teest
+teest = c|d`
+	synta, err := ParseSynta(input)
+	assert.Nil(t, err)
+
+	assert.Len(t, synta.Nodes, 3)
+	assert.Equal(t, Identifier("test"), synta.Nodes[0].Identifier)
+	assert.Equal(t, NodeTypeFilename, synta.Nodes[1].Type)
+	assert.Equal(t, Identifier("teest"), synta.Nodes[2].Identifier)
+}
+
+func TestParseSyntaWithMultipleFilenames(t *testing.T) {
+	input := `test = a|b
+> test.test
+> test-test.test`
+	_, err := ParseSynta(input)
+	assert.NotNil(t, err)
+}
+
+func TestParseSyntaDefinitionsOnly(t *testing.T) {
+	input := `; the year
+year = [0-9]{4}
+ext = pdf|txt`
+	_, err := ParseSynta(input)
+	assert.NotNil(t, err)
+
+	synta, err := ParseSyntaWithOptions(input, Options{DefinitionsOnly: true})
+	assert.Nil(t, err)
+	assert.Len(t, synta.Definitions, 2)
+	assert.Equal(t, []string{"the year"}, synta.Definitions["year"].Comments)
+	assert.Len(t, synta.Nodes, 2)
+	assert.Empty(t, synta.Filename.Segments)
+
+	_, err = ParseSyntaWithOptions(input+"\n> year.ext", Options{DefinitionsOnly: true})
+	assert.EqualError(t, err, "a definitions file cannot declare a filename")
+	_, err = ParseSyntaWithOptions(input+"\n! separator = _", Options{DefinitionsOnly: true})
+	assert.EqualError(t, err, "a definitions file cannot contain directives")
+}
+
+func TestParseSyntaWithOnlyFilename(t *testing.T) {
+	input := `> word-year.ext`
+	_, err := ParseSynta(input)
+	assert.NotNil(t, err)
+
+	synta, err := ParseSyntaWithOptions(input, Options{Builtins: true})
+	assert.Nil(t, err)
+	assert.Len(t, synta.Definitions, 3)
+	assert.Len(t, synta.Nodes, 1)
+
+	values, err := synta.Extract("appunti-2024.pdf")
+	assert.Nil(t, err)
+	assert.Equal(t, map[Identifier]string{"word": "appunti", "year": "2024", "ext": "pdf"}, values)
+}
+
+func TestParseSyntaBuiltinsDoNotShadowDefinitions(t *testing.T) {
+	input := `> word.ext
+ext = pdf`
+	synta, err := ParseSyntaWithOptions(input, Options{Builtins: true})
+	assert.Nil(t, err)
+	assert.Equal(t, "pdf", synta.Definitions["ext"].Source())
+	assert.Equal(t, "[a-z]+", synta.Definitions["word"].Source())
+}
+
+func TestParseSyntaWithCommentPrefixes(t *testing.T) {
+	input := `# a hash comment
+// a slash comment
+test = a|b
+; a semicolon comment
+teest = c|d
+> test.teest`
+	_, err := ParseSynta(input)
+	assert.NotNil(t, err)
+
+	synta, err := ParseSyntaWithOptions(input, Options{CommentPrefixes: []string{";", "#", "//"}})
+	assert.Nil(t, err)
+	exp := StringDefintions{
+		"test":  {"a|b", []string{"a hash comment", "a slash comment"}},
+		"teest": {"c|d", []string{"a semicolon comment"}},
+	}
+	checkDefinitions(t, synta.Definitions, exp)
+
+	_, err = ParseSyntaWithOptions(input, Options{CommentPrefixes: []string{"#", "//"}})
+	assert.NotNil(t, err)
+}
+
+func TestParseSyntaWithFilenameComments(t *testing.T) {
+	input := `; a test comment
+test = a|b
+; the filename
+; on two lines
+> test.test`
+	synta, err := ParseSynta(input)
+	assert.Nil(t, err)
+
+	exp := StringDefintions{
+		"test": {"a|b", []string{"a test comment"}},
+	}
+	checkDefinitions(t, synta.Definitions, exp)
+	assert.Equal(t, []string{"the filename", "on two lines"}, synta.Filename.Comments)
+}
+
+func TestParseSyntaWithMaxLineLength(t *testing.T) {
+	input := `test = a|b
+
+long = ` + strings.Repeat("a", 100) + `
+> test.test`
+	_, err := ParseSyntaWithOptions(input, Options{MaxLineLength: 50})
+	assert.EqualError(t, err, "line 3 is 107 bytes long, exceeding the maximum of 50")
+
+	_, err = ParseSyntaWithOptions(input, Options{MaxLineLength: 107})
+	assert.Nil(t, err)
+	_, err = ParseSyntaWithOptions(input, Options{MaxLineLength: -1})
+	assert.Nil(t, err)
+
+	_, err = ParseSynta("test = " + strings.Repeat("a", DefaultMaxLineLength) + "\n> test.test")
+	assert.NotNil(t, err)
+}
+
+func TestParseSyntaWithLengthAnnotation(t *testing.T) {
+	synta, err := ParseSynta(`code = [a-z]+ len(3,5)
+short = [a-z]+ len(,2)
+ext = pdf
+> code-short.ext`)
+	assert.Nil(t, err)
+	assert.Equal(t, "[a-z]+", synta.Definitions["code"].Source())
+	assert.Equal(t, 3, synta.Definitions["code"].MinLen)
+	assert.Equal(t, 5, synta.Definitions["code"].MaxLen)
+	assert.Equal(t, "[a-z]+ len(,2)", synta.Definitions["short"].Expression())
+	assert.Equal(t, "pdf", synta.Definitions["ext"].Expression())
+
+	_, err = ParseSynta("code = [a-z]+ len(5,3)\n> code.code")
+	assert.NotNil(t, err)
+}
+
+func TestFilenameSeparatorCount(t *testing.T) {
+	synta := MustSynta(`test = a|b
+> test-test-test.test`)
+	assert.Equal(t, 3, synta.Filename.SeparatorCount())
+
+	synta = MustSynta(`test = a|b
+> test(-test(-test)?)?(-test)?.test`)
+	assert.Equal(t, 4, synta.Filename.SeparatorCount())
+
+	synta = MustSynta(`test = a|b
+> test.test`)
+	assert.Equal(t, 1, synta.Filename.SeparatorCount())
+
+	// literals are glued to the segments around them
+	synta = MustSynta(`test = a|b
+> test\-test.test`)
+	assert.Equal(t, 1, synta.Filename.SeparatorCount())
+	synta = MustSynta(`test = a|b
+> test\.test-test.test`)
+	assert.Equal(t, 2, synta.Filename.SeparatorCount())
+
+	synta = MustSynta(`test = a|b
+> test-test$`)
+	assert.Equal(t, 1, synta.Filename.SeparatorCount())
+}
+
+func TestFilenameMaxOptionalDepth(t *testing.T) {
+	synta := MustSynta(`test = a|b
+> test(-test(-test(-test)?)?)?(-test)?.test`)
+	assert.Equal(t, 3, synta.Filename.MaxOptionalDepth())
+
+	synta = MustSynta(`test = a|b
+> test(-test)?.test`)
+	assert.Equal(t, 1, synta.Filename.MaxOptionalDepth())
+
+	synta = MustSynta(`test = a|b
+> test.test`)
+	assert.Equal(t, 0, synta.Filename.MaxOptionalDepth())
+}
+
+func TestParseSyntaWithInlineSegments(t *testing.T) {
+	synta, err := ParseSynta(`name = [a-z]+
+ext = pdf
+> name-{[0-9]{4}}(-{v[0-9]+})?.ext`)
+	assert.Nil(t, err)
+	assert.Len(t, synta.Filename.Segments, 3)
+
+	inline := synta.Filename.Segments[1]
+	assert.Equal(t, SegmentType(SegmentTypeInline), inline.Kind)
+	assert.Nil(t, inline.Value)
+	assert.Equal(t, "[0-9]{4}", inline.Inline.Source())
+	assert.NotNil(t, inline.Inline.Regexp)
+	assert.Equal(t, "v[0-9]+", synta.Filename.Segments[2].Subsegments[0].Inline.Source())
+	assert.Equal(t, "name-{[0-9]{4}}(-{v[0-9]+})?.ext", synta.Filename.String())
+
+	_, err = ParseSynta("name = [a-z]+\n> name-{[0-9]{4}.name")
+	assert.NotNil(t, err)
+	_, err = ParseSynta("name = [a-z]+\n> name-{}.name")
+	assert.NotNil(t, err)
+	_, err = ParseSynta("name = [a-z]+\n> name-{+}.name")
+	assert.NotNil(t, err)
+	_, err = ParseSynta("name = [a-z]+\n> {[0-9]}name.name")
+	assert.NotNil(t, err)
+}
+
+func TestDefinitionHasAnchors(t *testing.T) {
+	assert.True(t, Definition{Pattern: "^foo$"}.HasAnchors())
+	assert.True(t, Definition{Pattern: "a|(b$)"}.HasAnchors())
+	assert.True(t, Definition{Pattern: `\Afoo`}.HasAnchors())
+	assert.False(t, Definition{Pattern: `[$^]+\^`}.HasAnchors())
+	assert.False(t, Definition{Pattern: "foo"}.HasAnchors())
+}
+
+func TestParseSyntaWithExtensionComment(t *testing.T) {
+	synta, err := ParseSynta(`name = [a-z]+
+ext = pdf|txt
+> name-{a ;b}.ext ; the format of the document`)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"the format of the document"}, synta.Filename.ExtensionComments)
+	assert.Equal(t, "a ;b", synta.Filename.Segments[1].Inline.Source())
+	assert.Equal(t, Identifier("ext"), synta.Filename.Extension)
+
+	synta, err = ParseSynta("name = [a-z]+\n> name.name")
+	assert.Nil(t, err)
+	assert.Nil(t, synta.Filename.ExtensionComments)
+}
+
+func TestParseSyntaWithOverrides(t *testing.T) {
+	input := `; the year of the exam
+year = [0-9]{2}
+name = [a-z]+
+ext = pdf
+> name-year.ext`
+
+	synta, err := ParseSyntaWithOverrides(input, map[Identifier]string{"year": "[0-9]{4}"})
+	assert.Nil(t, err)
+	assert.Equal(t, "[0-9]{4}", synta.Definitions["year"].Source())
+	assert.Equal(t, []string{"the year of the exam"}, synta.Definitions["year"].Comments)
+	assert.Equal(t, "[0-9]{4}", synta.Nodes[0].Definition.Source())
+
+	_, err = synta.Extract("exam-2024.pdf")
+	assert.Nil(t, err)
+	_, err = synta.Extract("exam-24.pdf")
+	assert.NotNil(t, err)
+
+	_, err = ParseSyntaWithOverrides(input, map[Identifier]string{"date": "[0-9]+"})
+	assert.Equal(t, "cannot override `date`, which is not defined", err.Error())
+	_, err = ParseSyntaWithOverrides(input, map[Identifier]string{"year": "[0-9"})
+	assert.NotNil(t, err)
+}
+
+func TestParseSyntaWithCRLF(t *testing.T) {
+	synta, err := ParseSynta("; the name\r\nname = [a-z]+\r\next = pdf|txt\r\n> name.ext\r\n")
+	assert.Nil(t, err)
+	assert.Equal(t, "[a-z]+", synta.Definitions["name"].Regexp.String())
+	assert.Equal(t, "pdf|txt", synta.Definitions["ext"].Regexp.String())
+	assert.Equal(t, []string{"the name"}, synta.Definitions["name"].Comments)
+	assert.Equal(t, Identifier("ext"), synta.Filename.Extension)
+
+	synta, err = ParseSynta("name = [a-z]+\rext = pdf\r> name.ext")
+	assert.Nil(t, err)
+	assert.Equal(t, "[a-z]+", synta.Definitions["name"].Regexp.String())
+}
+
+func TestParseSyntaWithAlternation(t *testing.T) {
+	synta, err := ParseSynta(`lesson = lesson
+lab = lab
+number = [0-9]{2}
+ext = pdf
+> (lesson|lab)-number.ext`)
+	assert.Nil(t, err)
+	alternation := synta.Filename.Segments[0]
+	assert.Equal(t, SegmentType(SegmentTypeAlternation), alternation.Kind)
+	assert.Nil(t, alternation.Value)
+	assert.Len(t, alternation.Subsegments, 2)
+	assert.Equal(t, Identifier("lab"), *alternation.Subsegments[1].Value)
+	assert.Equal(t, "(lesson|lab)-number.ext", synta.Filename.String())
+
+	synta, err = ParseSynta(`name = [a-z]+
+lesson = lesson
+lab = lab
+ext = pdf
+> name(-(lesson|lab|{ex[0-9]}))?.ext`)
+	assert.Nil(t, err)
+	optional := synta.Filename.Segments[1]
+	assert.Equal(t, SegmentType(SegmentTypeOptional), optional.Kind)
+	assert.Equal(t, SegmentType(SegmentTypeAlternation), optional.Subsegments[0].Kind)
+	assert.Len(t, optional.Subsegments[0].Subsegments, 3)
+	assert.Equal(t, "ex[0-9]", optional.Subsegments[0].Subsegments[2].Inline.Source())
+	assert.Equal(t, "name(-(lesson|lab|{ex[0-9]}))?.ext", synta.Filename.String())
+
+	_, err = ParseSynta("lesson = lesson\n> (lesson).lesson")
+	assert.NotNil(t, err)
+	_, err = ParseSynta("lesson = lesson\n> (lesson|).lesson")
+	assert.NotNil(t, err)
+	_, err = ParseSynta("lesson = lesson\n> (lesson|lab).lesson")
+	assert.Equal(t, "missing definition for `lab`", err.Error())
+}
+
+func TestParseSyntaWithOptionalAlternation(t *testing.T) {
+	synta, err := ParseSynta(`doc = doc
+draft = draft
+final = final
+ext = pdf
+> doc(-draft|-final|-{v[0-9]})?.ext`)
+	assert.Nil(t, err)
+	optional := synta.Filename.Segments[1]
+	assert.Equal(t, SegmentType(SegmentTypeOptional), optional.Kind)
+	assert.Len(t, optional.Subsegments, 1)
+	assert.Equal(t, SegmentType(SegmentTypeAlternation), optional.Subsegments[0].Kind)
+	assert.Len(t, optional.Subsegments[0].Subsegments, 3)
+	assert.Equal(t, "doc(-(draft|final|{v[0-9]}))?.ext", synta.Filename.String())
+
+	for filename, expected := range map[string]bool{
+		"doc.pdf":       true,
+		"doc-draft.pdf": true,
+		"doc-final.pdf": true,
+		"doc-v2.pdf":    true,
+		"doc-other.pdf": false,
+		"doc-.pdf":      false,
+	} {
+		matches, err := synta.Match(filename)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, matches, filename)
+	}
+
+	for _, input := range []string{
+		"doc = doc\n> doc(-doc|doc)?.doc",
+		"doc = doc\n> doc(-doc|-)?.doc",
+		"doc = doc\n> doc(-=doc|-doc)?.doc",
+		"doc = doc\n> doc(-(doc|doc)|-doc)?.doc",
+		"doc = doc\n> doc(-doc|-doc.doc",
+	} {
+		_, err = ParseSynta(input)
+		assert.NotNil(t, err, input)
+	}
+}
+
+func TestParseSyntaWithRepeat(t *testing.T) {
+	synta, err := ParseSynta(`name = [a-z]+
+tag = [a-z]+
+ext = pdf
+> name(-tag)+.ext`)
+	assert.Nil(t, err)
+	repeat := synta.Filename.Segments[1]
+	assert.Equal(t, SegmentType(SegmentTypeRepeat), repeat.Kind)
+	assert.Equal(t, Identifier("tag"), *repeat.Subsegments[0].Value)
+	assert.Equal(t, "name(-tag)+.ext", synta.Filename.String())
+
+	_, err = ParseSynta("name = [a-z]+\n> name(-name)*.name")
+	assert.NotNil(t, err)
+}
+
+func TestParseSyntaWithExtensionAlternation(t *testing.T) {
+	synta, err := ParseSynta(`name = [a-z]+
+pdf = pdf
+txt = txt
+> name.(pdf|txt)`)
+	assert.Nil(t, err)
+	assert.Equal(t, Identifier("pdf"), synta.Filename.Extension)
+	assert.Equal(t, []Identifier{"pdf", "txt"}, synta.Filename.Extensions)
+	assert.Equal(t, "name.(pdf|txt)", synta.Filename.String())
+
+	synta, err = ParseSynta(`name = [a-z]+
+jpg = jpe?g
+png = png
+gif = gif
+> name.(jpg|png|gif)`)
+	assert.Nil(t, err)
+	assert.Equal(t, []Identifier{"jpg", "png", "gif"}, synta.Filename.Extensions)
+	assert.Equal(t, "name.(jpg|png|gif)", synta.Filename.String())
+
+	_, err = ParseSynta("name = [a-z]+\njpg = jpg\n> name.(jpg|png)")
+	assert.Equal(t, "missing definition for `png`", err.Error())
+	_, err = ParseSynta("name = [a-z]+\npng = png\n> name.(jpg|png)")
+	assert.Equal(t, "missing definition for `jpg`", err.Error())
+	_, err = ParseSynta("name = [a-z]+\n> name.(name)")
+	assert.NotNil(t, err)
+	_, err = ParseSynta("name = [a-z]+\n> name.(name|name")
+	assert.NotNil(t, err)
+	_, err = ParseSynta("name = [a-z]+\n> name.(name|name)name")
+	assert.NotNil(t, err)
+}
+
+func TestParseSyntaWithUnicodeIdentifiers(t *testing.T) {
+	synta, err := ParseSynta(`Lesson = lezione
+città = [a-z]+
+ext = pdf
+> Lesson-città(-Lesson)?.ext`)
+	assert.Nil(t, err)
+	assert.Equal(t, Identifier("Lesson"), *synta.Filename.Segments[0].Value)
+	assert.Equal(t, Identifier("città"), *synta.Filename.Segments[1].Value)
+	assert.Equal(t, "Lesson-città(-Lesson)?.ext", synta.Filename.String())
+
+	values, err := synta.Extract("lezione-bologna.pdf")
+	assert.Nil(t, err)
+	assert.Equal(t, "bologna", values["città"])
+
+	_, err = ParseSynta("name = [a-z]+\n> name-Città.name")
+	assert.Equal(t, "missing definition for `Città`", err.Error())
+	_, err = ParseSynta("name = [a-z]+\n> name-näme2.name")
+	assert.NotNil(t, err)
+}
+
+func TestParseSyntaWithContinuedDefinitions(t *testing.T) {
+	synta, err := ParseSynta(`course = [a-z]+\
+  (?:-[a-z]+)*
+year = [0-9]{4}
+; the format
+ext = pdf|\
+    txt|\
+    md
+> course-year.ext`)
+	assert.Nil(t, err)
+	assert.Equal(t, "[a-z]+(?:-[a-z]+)*", synta.Definitions["course"].Source())
+	assert.Equal(t, "pdf|txt|md", synta.Definitions["ext"].Source())
+	assert.Equal(t, []string{"the format"}, synta.Definitions["ext"].Comments)
+	matches, err := synta.Match("analisi-matematica-2024.md")
+	assert.Nil(t, err)
+	assert.True(t, matches)
+
+	synta, err = ParseSynta("name = [a-z\\\\]+\n> name.name")
+	assert.Nil(t, err)
+	assert.Equal(t, `[a-z\\]+`, synta.Definitions["name"].Source())
+
+	_, err = ParseSynta("name = [a-z]+\n> name.ext\next = pdf|\\")
+	var se SyntaError
+	assert.ErrorAs(t, err, &se)
+	assert.Equal(t, SyntaError{3, 10, "dangling `\\` continuation at the end of the file", TokenPattern}, se)
+
+	_, err = ParseSynta("name = [a-z]+\\\n  [0-9\n> name.name")
+	assert.ErrorAs(t, err, &se)
+	assert.Equal(t, 1, se.Line)
+}
+
+func TestParseSyntaWithTrailingDefinitionComments(t *testing.T) {
+	synta, err := ParseSynta(`; the id
+id = [0-9]+ len(1,4) ; the numeric id
+sep = [ ;a-z]+
+semi = a\ ;b
+ext = pdf ;
+> id-sep-semi.ext`)
+	assert.Nil(t, err)
+	assert.Equal(t, "[0-9]+", synta.Definitions["id"].Source())
+	assert.Equal(t, 4, synta.Definitions["id"].MaxLen)
+	assert.Equal(t, []string{"the id", "the numeric id"}, synta.Definitions["id"].Comments)
+	assert.Equal(t, "[ ;a-z]+", synta.Definitions["sep"].Source())
+	assert.Nil(t, synta.Definitions["sep"].Comments)
+	assert.Equal(t, `a\ ;b`, synta.Definitions["semi"].Source())
+	assert.Equal(t, "pdf", synta.Definitions["ext"].Source())
+	assert.Equal(t, []string{""}, synta.Definitions["ext"].Comments)
+
+	synta, err = ParseSyntaWithOptions("id = [0-9]+ # the id\n> id.id", Options{CommentPrefixes: []string{"#"}})
+	assert.Nil(t, err)
+	assert.Equal(t, "[0-9]+", synta.Definitions["id"].Source())
+	assert.Equal(t, []string{"the id"}, synta.Definitions["id"].Comments)
+}
+
+func TestParseSyntaWithLiteralSegments(t *testing.T) {
+	synta, err := ParseSynta(`major = [0-9]+
+minor = [0-9]+
+name = [a-z]+
+tag = [a-z]+
+ext = pdf
+> name-major\.minor(-tag)?.ext`)
+	assert.Nil(t, err)
+	assert.Equal(t, SegmentType(SegmentTypeLiteral), synta.Filename.Segments[2].Kind)
+	assert.Equal(t, Identifier("."), *synta.Filename.Segments[2].Value)
+	assert.Contains(t, synta.String(), "> name-major\\.minor(-tag)?.ext")
+
+	values, err := synta.Extract("release-2.10-beta.pdf")
+	assert.Nil(t, err)
+	assert.Equal(t, map[Identifier]string{"name": "release", "tag": "beta", "major": "2", "minor": "10", "ext": "pdf"}, values)
+	matches, err := synta.Match("release-2-10.pdf")
+	assert.Nil(t, err)
+	assert.False(t, matches)
+
+	filename, err := synta.Render(map[Identifier]string{"name": "release", "major": "1", "minor": "0", "ext": "pdf"})
+	assert.Nil(t, err)
+	assert.Equal(t, "release-1.0.pdf", filename)
+
+	_, err = ParseSynta("name = [a-z]+\n> name\\x.name")
+	var se SyntaError
+	assert.ErrorAs(t, err, &se)
+	assert.Equal(t, 6, se.Pos)
+}
+
+func TestParseSyntaWithoutExtension(t *testing.T) {
+	synta, err := ParseSynta(`name = [A-Z]+
+num = [0-9]+
+> name(-num)?$ ; no extension`)
+	assert.Nil(t, err)
+	assert.Equal(t, Identifier(""), synta.Filename.Extension)
+	assert.False(t, synta.Filename.HasExtension())
+	assert.Equal(t, "name(-num)?$", synta.Filename.String())
+
+	values, err := synta.Extract("README-2")
+	assert.Nil(t, err)
+	assert.Equal(t, map[Identifier]string{"name": "README", "num": "2"}, values)
+	matches, err := synta.Match("README.md")
+	assert.Nil(t, err)
+	assert.False(t, matches)
+	filename, err := synta.Render(map[Identifier]string{"name": "LICENSE"})
+	assert.Nil(t, err)
+	assert.Equal(t, "LICENSE", filename)
+
+	_, err = ParseSynta("name = [A-Z]+\n> name")
+	var se SyntaError
+	assert.ErrorAs(t, err, &se)
+	assert.Contains(t, se.Msg, "Missing the extension, end the filename with a $ if it has none")
+	_, err = ParseSynta("name = [A-Z]+\n> name$-name")
+	assert.ErrorAs(t, err, &se)
+	assert.Contains(t, se.Msg, "Expected the end of the filename")
+}
+
+func TestParseSyntaFilenameErrors(t *testing.T) {
+	// the parser reads the filename through the lexer, so both locate the
+	// offending characters alike
+	for _, line := range []string{"> name€.ext", "> name-{[a-z].ext", "> name-{}.ext"} {
+		input := "name = [a-z]+\next = pdf\n" + line
+		_, err := ParseSynta(input)
+		var se SyntaError
+		assert.ErrorAs(t, err, &se)
+
+		l := NewLexer(input, Options{})
+		for err = nil; err == nil; {
+			_, err = l.NextToken()
+		}
+		var lexed SyntaError
+		assert.ErrorAs(t, err, &lexed)
+		assert.Equal(t, lexed.Pos, se.Pos)
+	}
+
+	_, err := ParseSynta("name = [a-z]+\next = pdf\n> name-{[a-z].ext")
+	assert.EqualError(t, err, "Invalid char at column 6:\nname-{[a-z].ext\n     ^\nUnterminated inline pattern, expected a }")
+	_, err = ParseSynta("name = [a-z]+\next = pdf\n> name.ext-name")
+	assert.EqualError(t, err, "Invalid char at column 9:\nname.ext-name\n        ^\nExpected the end of the filename")
+}
+
+func TestParseSyntaNodeLines(t *testing.T) {
+	synta, err := ParseSynta(`; the course
+course = [a-z]+\
+  (?:-[a-z]+)*
+
+! require tag unless year
+year = [0-9]{4}
+; the filename
+> course-year(-tag)?.ext
+tag = [a-z]+ ; a tag
+ext = pdf`)
+	assert.Nil(t, err)
+
+	lines := map[Identifier]int{}
+	for _, node := range synta.Nodes {
+		lines[node.Identifier] = node.Line
+	}
+	assert.Equal(t, map[Identifier]int{"course": 2, "year": 6, "": 8, "tag": 9, "ext": 10}, lines)
+}
+
+func TestParseSyntaStrict(t *testing.T) {
+	input := `; the name
+name = [a-z]+\
+  (?:-[a-z]+)?
+! key = name
+> name.name`
+	synta, err := ParseSyntaWithOptions(input, Options{Strict: true})
+	assert.Nil(t, err)
+	assert.Equal(t, "[a-z]+(?:-[a-z]+)?", synta.Definitions["name"].Source())
+
+	for contents, expected := range map[string]SyntaError{
+		"name = [a-z]+\n>name.name":             {2, 1, "expected `> ` followed by the filename", TokenFilename},
+		"name = [a-z]+\n  >  \n":                {2, 3, "expected `> ` followed by the filename", TokenFilename},
+		"name [a-z]+\n> name.name":              {1, 0, "expected a comment, a directive, `> <filename>` or `<id> = <regexp>`", TokenError},
+		"name = [a-z]+\nna_me = x\n> name.name": {2, 2, "invalid identifier `na_me`, expected `<id> = <regexp>`", TokenIdentifier},
+	} {
+		_, err := ParseSyntaWithOptions(contents, Options{Strict: true})
+		var se SyntaError
+		assert.ErrorAs(t, err, &se, contents)
+		assert.Equal(t, expected, se, contents)
+	}
+}
+
+func TestParseSyntaMultipleFilenames(t *testing.T) {
+	input := `course = [a-z]+
+year = [0-9]{4}
+tag = [a-z]+
+ext = pdf
+> course-year.ext
+; the undated ones
+> course-tag.ext`
+	_, err := ParseSynta(input)
+	assert.NotNil(t, err)
+
+	synta, err := ParseSyntaWithOptions(input, Options{MultipleFilenames: true})
+	assert.Nil(t, err)
+	assert.Equal(t, "course-year.ext", synta.Filename.String())
+	assert.Len(t, synta.Alternatives, 1)
+	assert.Equal(t, []string{"the undated ones"}, synta.Alternatives[0].Comments)
+
+	matches, err := synta.Match("algebra-exam.pdf")
+	assert.Nil(t, err)
+	assert.True(t, matches)
+	matches, err = synta.Match("algebra-2024.pdf")
+	assert.Nil(t, err)
+	assert.True(t, matches)
+	matches, err = synta.Match("algebra.pdf")
+	assert.Nil(t, err)
+	assert.False(t, matches)
+
+	reparsed, err := ParseSyntaWithOptions(synta.String(), Options{MultipleFilenames: true})
+	assert.Nil(t, err)
+	assert.True(t, synta.Equal(reparsed))
+
+	_, err = ParseSyntaWithOptions("name = [a-z]+\n> name.ext\n> name.name", Options{MultipleFilenames: true})
+	assert.NotNil(t, err)
+}
+
+func TestMultipleFilenamesOnlyFilenameMethods(t *testing.T) {
+	synta, err := ParseSyntaWithOptions(`course = [a-z]+
+year = [0-9]{4}
+tag = [a-z]+
+ext = pdf
+> course-year.ext
+> course-tag.ext`, Options{MultipleFilenames: true})
+	assert.Nil(t, err)
+
+	// the filename conforms to the alternative only
+	alternative := "algebra-exam.pdf"
+	matches, err := synta.Match(alternative)
+	assert.Nil(t, err)
+	assert.True(t, matches)
+
+	expr, err := synta.BuildRegexp()
+	assert.Nil(t, err)
+	assert.False(t, expr.MatchString(alternative))
+	_, err = synta.Extract(alternative)
+	assert.NotNil(t, err)
+	_, err = synta.ExtractAll(alternative)
+	assert.NotNil(t, err)
+	_, err = synta.Captures(alternative)
+	assert.NotNil(t, err)
+	_, err = synta.MatchPositions(alternative)
+	assert.NotNil(t, err)
+	matches, err = synta.MatchWithExtensions(alternative, []Identifier{"pdf"})
+	assert.Nil(t, err)
+	assert.False(t, matches)
+	distance, err := synta.Distance(alternative)
+	assert.Nil(t, err)
+	assert.Positive(t, distance)
+
+	_, err = synta.Render(map[Identifier]string{"course": "algebra", "tag": "exam", "ext": "pdf"})
+	assert.NotNil(t, err)
+	example, err := synta.Example()
+	assert.Nil(t, err)
+	assert.True(t, expr.MatchString(example))
+	assert.NotContains(t, synta.Summary(), "tag")
+}
@@ -3,17 +3,28 @@ package regexp
 import (
 	"fmt"
 	"regexp"
+	"strings"
 
 	"github.com/cartabinaria/synta"
 )
 
 func Convert(synta synta.Synta) (expr *regexp.Regexp, err error) {
-	finalString, err := convertWithoutExtensionString(synta.Definitions, synta.Filename.Segments)
+	finalString, err := convertWithoutExtensionString(synta.Definitions, synta.Filename.Segments, separator(synta))
 	if err != nil {
 		return
 	}
 
-	finalString += "\\.(" + synta.Definitions[synta.Filename.Extension].Regexp.String() + ")"
+	exts := synta.Filename.Extensions
+	if len(exts) == 0 && synta.Filename.HasExtension() {
+		exts = append(exts, synta.Filename.Extension)
+	}
+	extensions := []string{}
+	for _, ext := range exts {
+		extensions = append(extensions, synta.Definitions[ext].Source())
+	}
+	if len(extensions) > 0 {
+		finalString += "\\.(" + strings.Join(extensions, "|") + ")"
+	}
 	expr, err = regexp.Compile("^" + finalString + "$")
 
 	// Simplify when we use regexp/syntax
@@ -23,12 +34,12 @@ func Convert(synta synta.Synta) (expr *regexp.Regexp, err error) {
 	return
 }
 
-func convertWithoutExtensionString(definitions map[synta.Identifier]synta.Definition, segments []synta.Segment) (expr string, err error) {
+func convertWithoutExtensionString(definitions map[synta.Identifier]synta.Definition, segments []synta.Segment, sep string) (expr string, err error) {
 	for i, segment := range segments {
 		definition := synta.Definition{}
 
 		switch segment.Kind {
-		case synta.SegmentTypeIdentifier:
+		case synta.SegmentTypeIdentifier, synta.SegmentTypeBackreference:
 			def, isPresent := definitions[*segment.Value]
 			if !isPresent {
 				err = fmt.Errorf("Missing definition for %s", *segment.Value)
@@ -36,28 +47,59 @@ func convertWithoutExtensionString(definitions map[synta.Identifier]synta.Defini
 			}
 
 			definition = def
-			expr += "(" + definition.Regexp.String() + ")"
+			expr += "(" + definition.Source() + ")"
+		case synta.SegmentTypeInline:
+			expr += "(" + segment.Inline.Source() + ")"
 		case synta.SegmentTypeOptional:
-			exp, e := convertWithoutExtensionString(definitions, segment.Subsegments)
+			exp, e := convertWithoutExtensionString(definitions, segment.Subsegments, sep)
 			if e != nil {
 				err = e
 				return
 			}
-			expr += "(-" + exp + ")?"
+			expr += "(" + sep + exp + ")?"
+		case synta.SegmentTypeAlternation:
+			branches := []string{}
+			for _, branch := range segment.Subsegments {
+				exp, e := convertWithoutExtensionString(definitions, []synta.Segment{branch}, sep)
+				if e != nil {
+					err = e
+					return
+				}
+				branches = append(branches, exp)
+			}
+			expr += "(" + strings.Join(branches, "|") + ")"
+		case synta.SegmentTypeRepeat:
+			exp, e := convertWithoutExtensionString(definitions, segment.Subsegments, sep)
+			if e != nil {
+				err = e
+				return
+			}
+			expr += "(" + sep + exp + ")+"
+		case synta.SegmentTypeLiteral:
+			expr += regexp.QuoteMeta(string(*segment.Value))
 		}
 
-		if i != len(segments)-1 && segments[i+1].Kind != synta.SegmentTypeOptional {
-			expr += "-"
+		if next := i + 1; next < len(segments) && segments[next].Kind != synta.SegmentTypeOptional && segments[next].Kind != synta.SegmentTypeRepeat &&
+			segment.Kind != synta.SegmentTypeLiteral && segments[next].Kind != synta.SegmentTypeLiteral {
+			expr += sep
 		}
 	}
 	return
 }
 
 func ConvertWithoutExtension(synta synta.Synta) (expr *regexp.Regexp, err error) {
-	exp, err := convertWithoutExtensionString(synta.Definitions, synta.Filename.Segments)
+	exp, err := convertWithoutExtensionString(synta.Definitions, synta.Filename.Segments, separator(synta))
 	if err != nil {
 		return
 	}
 	expr, err = regexp.Compile("^" + exp + "$")
 	return
 }
+
+// separator returns the pattern of the separator standing between segments
+func separator(s synta.Synta) string {
+	if s.Separator == "" {
+		return "-"
+	}
+	return regexp.QuoteMeta(s.Separator)
+}
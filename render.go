@@ -0,0 +1,216 @@
+package synta
+
+import (
+	"fmt"
+)
+
+// Render builds the filename described by the values of its identifiers,
+// performing the inverse of Extract. Values may also be keyed by segment, as
+// by Captures, an identifier appearing more than once taking the value of its
+// own key when given, and the value of the identifier otherwise. An optional
+// segment is rendered when one of its own segments has a value under its
+// Captures key, and left out otherwise, while a repeated segment is rendered
+// once. Every value must match the definition of its identifier. Inline
+// segments can only be rendered when their pattern matches a single value.
+func (s Synta) Render(values map[Identifier]string) (filename string, err error) {
+	known := map[Identifier]Identifier{}
+	occurrences := map[Identifier]int{}
+	for _, id := range s.Filename.identifiers() {
+		known[id] = id
+		known[nextCaptureKey(occurrences, id)] = id
+	}
+	for key, value := range values {
+		id, ok := known[key]
+		if !ok {
+			err = fmt.Errorf("`%s` is not part of the filename", key)
+			return
+		}
+		matches, e := matchesDefinition(s.Definitions[id], value)
+		if e != nil {
+			err = e
+			return
+		}
+		if !matches {
+			err = fmt.Errorf("value `%s` does not match the definition of `%s`", value, id)
+			return
+		}
+	}
+
+	r := renderer{values, s.separator(), map[Identifier]int{}}
+	if filename, err = r.renderSegments(s.Filename.Segments); err != nil {
+		return "", err
+	}
+	if !s.Filename.HasExtension() {
+		return
+	}
+	for _, id := range s.Filename.extensions() {
+		if ext, ok := r.value(id); ok {
+			filename += "." + ext
+			return
+		}
+	}
+	return "", fmt.Errorf("missing value for `%s`", s.Filename.formatExtension())
+}
+
+// nextCaptureKey counts a further occurrence of the identifier and returns
+// its key, as reported by Captures
+func nextCaptureKey(occurrences map[Identifier]int, id Identifier) Identifier {
+	occurrences[id]++
+	if occurrences[id] > 1 {
+		return Identifier(fmt.Sprintf("%s_%d", id, occurrences[id]))
+	}
+	return id
+}
+
+// renderer renders segments, counting the occurrences of each identifier in
+// the same order as the regexp built by Captures does
+type renderer struct {
+	values      map[Identifier]string
+	sep         string
+	occurrences map[Identifier]int
+}
+
+// value returns the value of the next occurrence of the identifier, falling
+// back to the value of the identifier itself
+func (r renderer) value(id Identifier) (value string, ok bool) {
+	if value, ok = r.values[nextCaptureKey(r.occurrences, id)]; !ok {
+		value, ok = r.values[id]
+	}
+	return
+}
+
+// skip counts the occurrences of the identifiers of segments left out
+func (r renderer) skip(segments []Segment) {
+	for _, id := range getAllIdentifiers(segments) {
+		nextCaptureKey(r.occurrences, id)
+	}
+}
+
+func (r renderer) renderSegments(segments []Segment) (filename string, err error) {
+	for i, segment := range segments {
+		switch segment.Kind {
+		case SegmentTypeIdentifier, SegmentTypeBackreference:
+			value, ok := r.value(*segment.Value)
+			if !ok {
+				err = fmt.Errorf("missing value for `%s`", *segment.Value)
+				return
+			}
+			filename += value
+		case SegmentTypeInline:
+			literal, ok := finiteStrings(segment.Inline.Source(), 2)
+			if !ok || len(literal) != 1 {
+				err = fmt.Errorf("cannot render `{%s}`, which matches more than one value", segment.Inline.Source())
+				return
+			}
+			filename += literal[0]
+		case SegmentTypeLiteral:
+			filename += string(*segment.Value)
+		case SegmentTypeAlternation:
+			branch, ok := r.renderedBranch(segment)
+			if !ok {
+				err = fmt.Errorf("missing value for one of `%s`", formatBranches(segment.Subsegments))
+				return
+			}
+			r.skip(segment.Subsegments[:branch])
+			inner, e := r.renderSegments(segment.Subsegments[branch : branch+1])
+			if e != nil {
+				err = e
+				return
+			}
+			r.skip(segment.Subsegments[branch+1:])
+			filename += inner
+		case SegmentTypeRepeat:
+			inner, e := r.renderSegments(segment.Subsegments)
+			if e != nil {
+				err = e
+				return
+			}
+			if leadingGroup(segments, i) {
+				filename += inner + r.sep
+				continue
+			}
+			filename += r.sep + inner
+		case SegmentTypeOptional:
+			if !r.optionalPresent(segment) {
+				r.skip(segment.Subsegments)
+				if leadingGroup(segments, i) {
+					continue
+				}
+				break
+			}
+			inner, e := r.renderSegments(segment.Subsegments)
+			if e != nil {
+				err = e
+				return
+			}
+			if leadingGroup(segments, i) {
+				filename += inner + r.sep
+				continue
+			}
+			filename += r.sep + inner
+		}
+
+		if separated(segments, i) {
+			filename += r.sep
+		}
+	}
+	return
+}
+
+// optionalPresent tells whether any identifier or backreference of the
+// optional segment, nested optionals excluded, has a value under its own key
+func (r renderer) optionalPresent(optional Segment) bool {
+	occurrences := map[Identifier]int{}
+	for id, n := range r.occurrences {
+		occurrences[id] = n
+	}
+	lookahead := renderer{r.values, r.sep, occurrences}
+
+	for _, sub := range optional.Subsegments {
+		switch sub.Kind {
+		case SegmentTypeIdentifier, SegmentTypeBackreference:
+			if _, ok := r.values[nextCaptureKey(occurrences, *sub.Value)]; ok {
+				return true
+			}
+			continue
+		case SegmentTypeAlternation:
+			if branch, ok := lookahead.renderedBranch(sub); ok && sub.Subsegments[branch].Kind != SegmentTypeInline {
+				lookahead.skip(sub.Subsegments[:branch])
+				if _, ok := r.values[nextCaptureKey(occurrences, *sub.Subsegments[branch].Value)]; ok {
+					return true
+				}
+				lookahead.skip(sub.Subsegments[branch+1:])
+				continue
+			}
+		}
+		lookahead.skip([]Segment{sub})
+	}
+	return false
+}
+
+// renderedBranch picks the index of the branch of an alternation to render:
+// the first identifier with a value or, failing that, the first inline
+// segment
+func (r renderer) renderedBranch(alternation Segment) (branch int, ok bool) {
+	occurrences := map[Identifier]int{}
+	for id, n := range r.occurrences {
+		occurrences[id] = n
+	}
+	for i, sub := range alternation.Subsegments {
+		if sub.Kind == SegmentTypeIdentifier || sub.Kind == SegmentTypeBackreference {
+			key := nextCaptureKey(occurrences, *sub.Value)
+			if _, ok = r.values[key]; ok {
+				return i, true
+			}
+			if _, ok = r.values[*sub.Value]; ok {
+				return i, true
+			}
+		}
+	}
+	for i, sub := range alternation.Subsegments {
+		if sub.Kind == SegmentTypeInline {
+			return i, true
+		}
+	}
+	return
+}
@@ -0,0 +1,85 @@
+package synta
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRender(t *testing.T) {
+	synta, err := ParseSynta(extractInput)
+	assert.Nil(t, err)
+
+	filename, err := synta.Render(map[Identifier]string{"course": "analisi", "year": "2024", "tag": "esame", "ext": "pdf"})
+	assert.Nil(t, err)
+	assert.Equal(t, "analisi-2024-esame.pdf", filename)
+
+	filename, err = synta.Render(map[Identifier]string{"course": "analisi", "year": "2024", "ext": "pdf"})
+	assert.Nil(t, err)
+	assert.Equal(t, "analisi-2024.pdf", filename)
+}
+
+func TestRenderWithRepeatedIdentifier(t *testing.T) {
+	synta := MustSynta(`name = [a-z]+
+ext = pdf
+> name(-name)?.ext`)
+
+	filename, err := synta.Render(map[Identifier]string{"name": "abc", "ext": "pdf"})
+	assert.Nil(t, err)
+	assert.Equal(t, "abc.pdf", filename)
+	filename, err = synta.Render(map[Identifier]string{"name": "abc", "name_2": "def", "ext": "pdf"})
+	assert.Nil(t, err)
+	assert.Equal(t, "abc-def.pdf", filename)
+
+	synta = MustSynta(`name = [a-z]+
+year = [0-9]{4}
+ext = pdf
+> name(-year|-name)?.ext`)
+	filename, err = synta.Render(map[Identifier]string{"name": "abc", "ext": "pdf"})
+	assert.Nil(t, err)
+	assert.Equal(t, "abc.pdf", filename)
+	filename, err = synta.Render(map[Identifier]string{"name": "abc", "name_2": "def", "ext": "pdf"})
+	assert.Nil(t, err)
+	assert.Equal(t, "abc-def.pdf", filename)
+	filename, err = synta.Render(map[Identifier]string{"name": "abc", "year": "2024", "ext": "pdf"})
+	assert.Nil(t, err)
+	assert.Equal(t, "abc-2024.pdf", filename)
+
+	captures, err := synta.Captures("abc-def.pdf")
+	assert.Nil(t, err)
+	filename, err = synta.Render(captures)
+	assert.Nil(t, err)
+	assert.Equal(t, "abc-def.pdf", filename)
+}
+
+func TestRenderWithOptionalBackreference(t *testing.T) {
+	synta := MustSynta(`name = [a-z]+
+ext = pdf
+> name(-=name)?.ext`)
+
+	filename, err := synta.Render(map[Identifier]string{"name": "abc", "ext": "pdf"})
+	assert.Nil(t, err)
+	assert.Equal(t, "abc.pdf", filename)
+
+	captures, err := synta.Captures("abc-abc.pdf")
+	assert.Nil(t, err)
+	filename, err = synta.Render(captures)
+	assert.Nil(t, err)
+	assert.Equal(t, "abc-abc.pdf", filename)
+}
+
+func TestRenderWithInvalidValues(t *testing.T) {
+	synta, err := ParseSynta(extractInput)
+	assert.Nil(t, err)
+
+	_, err = synta.Render(map[Identifier]string{"course": "analisi", "ext": "pdf"})
+	assert.EqualError(t, err, "missing value for `year`")
+	_, err = synta.Render(map[Identifier]string{"course": "analisi", "year": "24", "ext": "pdf"})
+	assert.EqualError(t, err, "value `24` does not match the definition of `year`")
+	_, err = synta.Render(map[Identifier]string{"course": "analisi", "year": "2024", "ext": "pdf", "author": "rossi"})
+	assert.EqualError(t, err, "`author` is not part of the filename")
+
+	filename, err := MustSynta("name = [a-z]+\nyear = [0-9]{4}\next = pdf\n> name-year.ext").Render(map[Identifier]string{"name": "abc"})
+	assert.EqualError(t, err, "missing value for `year`")
+	assert.Equal(t, "", filename)
+}
@@ -0,0 +1,17 @@
+package synta
+
+import (
+	"sort"
+)
+
+// RestrictTo returns the identifiers of the definitions which are not part of
+// the allowed vocabulary, sorted alphabetically
+func (s Synta) RestrictTo(allowed map[Identifier]bool) (disallowed []Identifier) {
+	for id := range s.Definitions {
+		if !allowed[id] {
+			disallowed = append(disallowed, id)
+		}
+	}
+	sort.Slice(disallowed, func(i, j int) bool { return disallowed[i] < disallowed[j] })
+	return
+}
@@ -0,0 +1,22 @@
+package synta
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestrictTo(t *testing.T) {
+	synta := MustSynta(`name = [a-z]+
+whatever = [0-9]+
+ext = pdf
+anything = .*
+> name-whatever.ext`)
+
+	allowed := map[Identifier]bool{"name": true, "ext": true, "year": true}
+	assert.Equal(t, []Identifier{"anything", "whatever"}, synta.RestrictTo(allowed))
+
+	allowed["whatever"] = true
+	allowed["anything"] = true
+	assert.Empty(t, synta.RestrictTo(allowed))
+}
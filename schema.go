@@ -0,0 +1,63 @@
+package synta
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/invopop/jsonschema"
+)
+
+// segmentSchema describes segmentJSON, whose kind is one of the names of the
+// segment types
+type segmentSchema struct {
+	Kind        string          `json:"kind" jsonschema:"enum=Identifier,enum=Optional,enum=Backreference,enum=Inline,enum=Alternation,enum=Repeat,enum=Literal"`
+	Value       string          `json:"value,omitempty"`
+	Inline      *definitionJSON `json:"inline,omitempty"`
+	Subsegments []segmentSchema `json:"subsegments,omitempty"`
+}
+
+// filenameSchema describes filenameJSON
+type filenameSchema struct {
+	Segments          []segmentSchema `json:"segments"`
+	Extension         string          `json:"extension"`
+	Extensions        []string        `json:"extensions,omitempty"`
+	Comments          []string        `json:"comments,omitempty"`
+	ExtensionComments []string        `json:"extensionComments,omitempty"`
+}
+
+// constraintSchema describes Constraint, whose kind is one of the names of
+// the constraint kinds
+type constraintSchema struct {
+	Kind    string `json:"kind" jsonschema:"enum=RequiredUnless,enum=Equal"`
+	Subject string `json:"subject"`
+	Other   string `json:"other,omitempty"`
+}
+
+// syntaSchema describes syntaJSON
+type syntaSchema struct {
+	Definitions    map[string]definitionJSON `json:"definitions"`
+	Filename       filenameSchema            `json:"filename"`
+	Constraints    []constraintSchema        `json:"constraints,omitempty"`
+	Key            string                    `json:"key,omitempty"`
+	LowercaseInput bool                      `json:"lowercaseInput,omitempty"`
+	MatchStrategy  string                    `json:"matchStrategy,omitempty" jsonschema:"enum=MatchGreedy,enum=PreferFewestOptionals,enum=PreferMostOptionals,enum=FirstVariant"`
+	Separator      string                    `json:"separator,omitempty"`
+	Alternatives   []filenameSchema          `json:"alternatives,omitempty"`
+}
+
+// JSONSchema returns the JSON schema of a spec as encoded by its MarshalJSON
+func JSONSchema() *jsonschema.Schema {
+	r := jsonschema.Reflector{
+		// the schema types are named after the ones they describe
+		Namer: func(t reflect.Type) string {
+			name := strings.TrimSuffix(strings.TrimSuffix(t.Name(), "Schema"), "JSON")
+			if name == "" {
+				return name
+			}
+			return strings.ToUpper(name[:1]) + name[1:]
+		},
+	}
+	s := r.Reflect(&syntaSchema{})
+	s.ID = "https://github.com/cartabinaria/synta/synta"
+	return s
+}
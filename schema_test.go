@@ -0,0 +1,45 @@
+package synta
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONSchema(t *testing.T) {
+	schema := JSONSchema()
+	assert.Equal(t, "#/$defs/Synta", schema.Ref)
+
+	synta := MustSynta(`name = [a-z]+ len(2,)
+tag = [a-z]+
+ext = pdf
+! separator = _
+> name(-tag)?.ext`)
+	data, err := json.Marshal(synta)
+	assert.Nil(t, err)
+	var encoded map[string]any
+	assert.Nil(t, json.Unmarshal(data, &encoded))
+
+	// every key of the encoded spec is described by the schema
+	properties := func(def string) []string {
+		keys := []string{}
+		for pair := schema.Definitions[def].Properties.Oldest(); pair != nil; pair = pair.Next() {
+			keys = append(keys, pair.Key)
+		}
+		return keys
+	}
+	for key := range encoded {
+		assert.Contains(t, properties("Synta"), key)
+	}
+	for key := range encoded["definitions"].(map[string]any)["name"].(map[string]any) {
+		assert.Contains(t, properties("Definition"), key)
+	}
+	filename := encoded["filename"].(map[string]any)
+	for key := range filename {
+		assert.Contains(t, properties("Filename"), key)
+	}
+	for key := range filename["segments"].([]any)[1].(map[string]any) {
+		assert.Contains(t, properties("Segment"), key)
+	}
+}
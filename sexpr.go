@@ -0,0 +1,68 @@
+package synta
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SExpr serializes the spec as S-expressions, one per line: the definitions
+// sorted by identifier, such as `(def name "[a-z]+")`, then the directives,
+// such as `(require tag unless name)`, and finally the filename, such as
+// `(filename (seg name) (opt (seg tag)) (ext ext))`. Comments are omitted.
+func (s Synta) SExpr() string {
+	ids := []string{}
+	for id := range s.Definitions {
+		ids = append(ids, string(id))
+	}
+	sort.Strings(ids)
+
+	lines := []string{}
+	for _, id := range ids {
+		def := s.Definitions[Identifier(id)]
+		line := "(def " + id + " " + strconv.Quote(def.Source())
+		if def.MinLen != 0 || def.MaxLen != 0 {
+			line += " (len " + strconv.Itoa(def.MinLen) + " " + strconv.Itoa(def.MaxLen) + ")"
+		}
+		lines = append(lines, line+")")
+	}
+
+	for _, c := range s.Constraints {
+		if c.Kind == ConstraintRequiredUnless {
+			lines = append(lines, "(require "+string(c.Subject)+" unless "+string(c.Other)+")")
+		}
+	}
+
+	exts := []string{}
+	for _, ext := range s.Filename.extensions() {
+		exts = append(exts, string(ext))
+	}
+	filename := sexprSegments(s.Filename.Segments)
+	if len(exts) > 0 {
+		filename = append(filename, "(ext "+strings.Join(exts, " ")+")")
+	}
+	lines = append(lines, "(filename "+strings.Join(filename, " ")+")")
+	return strings.Join(lines, "\n")
+}
+
+func sexprSegments(segments []Segment) (exprs []string) {
+	for _, segment := range segments {
+		switch segment.Kind {
+		case SegmentTypeIdentifier:
+			exprs = append(exprs, "(seg "+string(*segment.Value)+")")
+		case SegmentTypeBackreference:
+			exprs = append(exprs, "(ref "+string(*segment.Value)+")")
+		case SegmentTypeInline:
+			exprs = append(exprs, "(inline "+strconv.Quote(segment.Inline.Source())+")")
+		case SegmentTypeLiteral:
+			exprs = append(exprs, "(lit "+strconv.Quote(string(*segment.Value))+")")
+		case SegmentTypeOptional:
+			exprs = append(exprs, "(opt "+strings.Join(sexprSegments(segment.Subsegments), " ")+")")
+		case SegmentTypeRepeat:
+			exprs = append(exprs, "(rep "+strings.Join(sexprSegments(segment.Subsegments), " ")+")")
+		case SegmentTypeAlternation:
+			exprs = append(exprs, "(alt "+strings.Join(sexprSegments(segment.Subsegments), " ")+")")
+		}
+	}
+	return
+}
@@ -0,0 +1,32 @@
+package synta
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSExpr(t *testing.T) {
+	synta := MustSynta(`; the name
+name = [a-z]+
+tag = [a-z]+ len(2,)
+ext = pdf
+> name(-tag)?.ext`)
+	assert.Equal(t, `(def ext "pdf")
+(def name "[a-z]+")
+(def tag "[a-z]+" (len 2 0))
+(filename (seg name) (opt (seg tag)) (ext ext))`, synta.SExpr())
+}
+
+func TestSExprWithDirectivesAndSpecialSegments(t *testing.T) {
+	synta := MustSynta(`name = [a-z]+
+num = "[0-9]+"
+ext = pdf
+! require num unless name
+> name(-num(-=name)?)?-{v[0-9]}.ext`)
+	assert.Equal(t, `(def ext "pdf")
+(def name "[a-z]+")
+(def num "\"[0-9]+\"")
+(require num unless name)
+(filename (seg name) (opt (seg num) (opt (ref name))) (inline "v[0-9]") (ext ext))`, synta.SExpr())
+}
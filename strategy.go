@@ -0,0 +1,113 @@
+package synta
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+)
+
+// MatchStrategy selects the interpretation returned by Extract when a
+// filename can be matched by the spec in more than one way, depending on
+// which optional segments are deemed present
+type MatchStrategy uint
+
+const (
+	// MatchGreedy lets the regexp engine decide, which considers optional
+	// segments present whenever possible, from left to right
+	MatchGreedy MatchStrategy = iota
+	// PreferFewestOptionals picks the interpretation with the fewest
+	// identifiers, so with the fewest optional segments present
+	PreferFewestOptionals
+	// PreferMostOptionals picks the interpretation with the most identifiers,
+	// so with the most optional segments present
+	PreferMostOptionals
+	// FirstVariant picks the first interpretation in declaration order, where
+	// an optional segment being absent comes before it being present
+	FirstVariant
+)
+
+var matchStrategyNames = []string{
+	MatchGreedy:           "MatchGreedy",
+	PreferFewestOptionals: "PreferFewestOptionals",
+	PreferMostOptionals:   "PreferMostOptionals",
+	FirstVariant:          "FirstVariant",
+}
+
+func (m MatchStrategy) String() string {
+	if int(m) < len(matchStrategyNames) {
+		return matchStrategyNames[m]
+	}
+	return fmt.Sprintf("MatchStrategy(%d)", uint(m))
+}
+
+// MarshalText encodes the strategy by name, such as "FirstVariant"
+func (m MatchStrategy) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText decodes the strategy from its name, failing on unknown
+// strategies
+func (m *MatchStrategy) UnmarshalText(text []byte) error {
+	i := slices.Index(matchStrategyNames, string(text))
+	if i < 0 {
+		return fmt.Errorf("unknown match strategy `%s`", text)
+	}
+	*m = MatchStrategy(i)
+	return nil
+}
+
+// strategyMatcher matches filenames against every variant of a spec, with
+// the regexps of the variants compiled once, so that the spec's strategy can
+// choose among their interpretations
+type strategyMatcher struct {
+	synta    Synta
+	variants []compiledVariant
+}
+
+// compiledVariant is the regexp matching a variant of the filename, along
+// with the identifiers of its groups and the number of its segments
+type compiledVariant struct {
+	expr  *regexp.Regexp
+	names map[string]Identifier
+	size  int
+}
+
+// strategyMatcher compiles the regexp of every variant of the spec
+func (s Synta) strategyMatcher() (m *strategyMatcher, err error) {
+	m = &strategyMatcher{synta: s}
+	for _, variant := range variants(s.Filename.Segments) {
+		expr, names, e := s.variantRegexp(variant)
+		if e != nil {
+			return nil, e
+		}
+		m.variants = append(m.variants, compiledVariant{expr, names, len(variant)})
+	}
+	return
+}
+
+// extract matches the filename against every variant and returns the values
+// of the one chosen by the spec's strategy. Ties are broken in favour of the
+// first variant.
+func (m *strategyMatcher) extract(filename string) (values map[Identifier]string, err error) {
+	s := m.synta
+	chosen := -1
+	for _, variant := range m.variants {
+		candidate, captures := captureValues(variant.expr, variant.names, s.input(filename))
+		if candidate == nil || s.checkCaptures(captures) != nil {
+			continue
+		}
+		if chosen < 0 ||
+			s.MatchStrategy == PreferFewestOptionals && variant.size < chosen ||
+			s.MatchStrategy == PreferMostOptionals && variant.size > chosen {
+			values, chosen = candidate, variant.size
+		}
+		if s.MatchStrategy == FirstVariant {
+			break
+		}
+	}
+
+	if values == nil {
+		err = fmt.Errorf("filename `%s` does not match the spec", filename)
+	}
+	return
+}
@@ -0,0 +1,48 @@
+package synta
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractWithMatchStrategy(t *testing.T) {
+	input := `name = [a-z]+(-[a-z]+)?
+author = [a-z]+
+tag = [a-z]+
+ext = pdf
+> name(-author)?(-tag)?.ext`
+
+	extract := func(strategy MatchStrategy) map[Identifier]string {
+		synta, err := ParseSyntaWithOptions(input, Options{MatchStrategy: strategy})
+		assert.Nil(t, err)
+		values, err := synta.Extract("a-b-c.pdf")
+		assert.Nil(t, err)
+
+		// the Matcher compiles the variants once and extracts alike
+		m, err := synta.Matcher()
+		assert.Nil(t, err)
+		reused, err := m.Extract("a-b-c.pdf")
+		assert.Nil(t, err)
+		assert.Equal(t, values, reused)
+		return values
+	}
+
+	assert.Equal(t, map[Identifier]string{"name": "a-b", "author": "c", "ext": "pdf"}, extract(MatchGreedy))
+	assert.Equal(t, map[Identifier]string{"name": "a-b", "tag": "c", "ext": "pdf"}, extract(FirstVariant))
+	assert.Equal(t, map[Identifier]string{"name": "a-b", "tag": "c", "ext": "pdf"}, extract(PreferFewestOptionals))
+	assert.Equal(t, map[Identifier]string{"name": "a", "author": "b", "tag": "c", "ext": "pdf"}, extract(PreferMostOptionals))
+}
+
+func TestExtractWithMatchStrategyAndNonMatchingFilename(t *testing.T) {
+	synta, err := ParseSyntaWithOptions(extractInput, Options{MatchStrategy: PreferMostOptionals})
+	assert.Nil(t, err)
+
+	_, err = synta.Extract("analisi-24.pdf")
+	assert.EqualError(t, err, "filename `analisi-24.pdf` does not match the spec")
+
+	m, err := synta.Matcher()
+	assert.Nil(t, err)
+	_, err = m.Extract("analisi-24.pdf")
+	assert.EqualError(t, err, "filename `analisi-24.pdf` does not match the spec")
+}
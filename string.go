@@ -0,0 +1,104 @@
+package synta
+
+import (
+	"io"
+	"sort"
+	"strings"
+)
+
+// declarationOrder returns the identifiers of the definitions in the order in
+// which they were declared, followed in alphabetical order by the ones
+// missing from Nodes
+func (s Synta) declarationOrder() []Identifier {
+	order := []Identifier{}
+	seen := map[Identifier]bool{}
+	for _, node := range s.Nodes {
+		if _, ok := s.Definitions[node.Identifier]; ok && node.Type == NodeTypeDefinition && !seen[node.Identifier] {
+			order = append(order, node.Identifier)
+			seen[node.Identifier] = true
+		}
+	}
+	rest := []Identifier{}
+	for id := range s.Definitions {
+		if !seen[id] {
+			rest = append(rest, id)
+		}
+	}
+	sort.Slice(rest, func(i, j int) bool { return rest[i] < rest[j] })
+	return append(order, rest...)
+}
+
+// A DeclaredDefinition is a definition along with its identifier
+type DeclaredDefinition struct {
+	ID  Identifier
+	Def Definition
+}
+
+// OrderedDefinitions returns the definitions of the spec, comments included,
+// in the order in which they were declared, followed in alphabetical order by
+// the ones missing from Nodes
+func (s Synta) OrderedDefinitions() (defs []DeclaredDefinition) {
+	defs = []DeclaredDefinition{}
+	for _, id := range s.declarationOrder() {
+		defs = append(defs, DeclaredDefinition{id, s.Definitions[id]})
+	}
+	return
+}
+
+// String serializes the spec back to the contents of a Synta file, which
+// parse to an equivalent spec. Definitions follow the order in which they
+// were declared, while the ones missing from Nodes, such as those added
+// programmatically, follow in alphabetical order. The directives and the
+// filename come last, followed by its alternatives, which parse back only
+// with Options.MultipleFilenames. Unlike format.Format, the file is not
+// normalized.
+func (s Synta) String() string {
+	var b strings.Builder
+	s.WriteTo(&b)
+	return b.String()
+}
+
+// WriteTo writes the spec to w as String serializes it, a line at a time, and
+// returns the number of bytes written. It implements io.WriterTo.
+func (s Synta) WriteTo(w io.Writer) (n int64, err error) {
+	write := func(text string) {
+		if err == nil {
+			var written int
+			written, err = io.WriteString(w, text)
+			n += int64(written)
+		}
+	}
+
+	for _, id := range s.declarationOrder() {
+		def := s.Definitions[id]
+		for _, comment := range def.Comments {
+			write("; " + comment + "\n")
+		}
+		write(string(id) + " = " + def.Expression() + "\n")
+	}
+	write("\n")
+
+	for _, c := range s.Constraints {
+		if c.IsDirective() {
+			write(c.String() + "\n")
+		}
+	}
+	if key, ok := s.Key(); ok {
+		write("! key = " + string(key) + "\n")
+	}
+	if s.Separator != "" {
+		write("! separator = " + s.Separator + "\n")
+	}
+
+	for _, f := range s.filenames() {
+		for _, comment := range f.Comments {
+			write("; " + comment + "\n")
+		}
+		filename := "> " + f.String()
+		if len(f.ExtensionComments) > 0 {
+			filename += " ; " + strings.Join(f.ExtensionComments, " ")
+		}
+		write(filename + "\n")
+	}
+	return
+}
@@ -0,0 +1,102 @@
+package synta
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyntaString(t *testing.T) {
+	inputs := []string{
+		`name = [a-z]+
+ext = pdf
+> name.ext`,
+		`; the course name
+course = [a-z]+
+; the year
+; of the exam
+year = [0-9]{4} len(4,)
+tag = [a-z]+
+author = [a-z]+
+ext = pdf|txt
+! require tag unless author
+! key = course
+; the exams of a course
+> course-year(-tag)?(-author(-tag)?)?.ext ; the format`,
+		`name = [a-z]+
+ext = pdf
+> name-{[0-9]{2}}-=name.ext`,
+	}
+
+	for _, input := range inputs {
+		synta := MustSynta(input)
+		parsed, err := ParseSynta(synta.String())
+		assert.Nil(t, err, input)
+		assert.Equal(t, synta.Definitions, parsed.Definitions, input)
+		assert.Equal(t, synta.Filename.String(), parsed.Filename.String(), input)
+		assert.Equal(t, synta.Filename.Comments, parsed.Filename.Comments, input)
+		assert.Equal(t, synta.Filename.ExtensionComments, parsed.Filename.ExtensionComments, input)
+		assert.Equal(t, synta.Constraints, parsed.Constraints, input)
+		assert.Equal(t, synta.key, parsed.key, input)
+		assert.Equal(t, synta.String(), parsed.String(), input)
+	}
+
+	synta := MustSynta(`name = [a-z]+
+unused = [0-9]+
+ext = pdf
+> name.ext`)
+	assert.Equal(t, "name = [a-z]+\next = pdf\n\n> name.ext\n", Clear(synta).String())
+}
+
+// failingWriter accepts a limited number of writes, then fails
+type failingWriter struct {
+	writes int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.writes == 0 {
+		return 0, errors.New("disk full")
+	}
+	w.writes--
+	return len(p), nil
+}
+
+func TestSyntaWriteTo(t *testing.T) {
+	synta := MustSynta(`; the name
+name = [a-z]+
+year = [0-9]{4} len(4,4)
+ext = pdf
+! require year unless name
+; the filename
+> name(-year)?.ext ; a pdf`)
+
+	var b bytes.Buffer
+	n, err := synta.WriteTo(&b)
+	assert.Nil(t, err)
+	assert.Equal(t, synta.String(), b.String())
+	assert.Equal(t, int64(len(synta.String())), n)
+
+	n, err = synta.WriteTo(&failingWriter{writes: 2})
+	assert.EqualError(t, err, "disk full")
+	assert.Equal(t, int64(len("; the name\nname = [a-z]+\n")), n)
+}
+
+func TestOrderedDefinitions(t *testing.T) {
+	synta := MustSynta(`; the course
+year = [0-9]{4}
+course = [a-z]+
+> course-year.ext
+; the format
+ext = pdf`)
+
+	ids := []Identifier{}
+	comments := [][]string{}
+	for _, d := range synta.OrderedDefinitions() {
+		ids = append(ids, d.ID)
+		comments = append(comments, d.Def.Comments)
+	}
+	assert.Equal(t, []Identifier{"year", "course", "ext"}, ids)
+	assert.Equal(t, [][]string{{"the course"}, nil, {"the format"}}, comments)
+}
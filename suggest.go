@@ -0,0 +1,96 @@
+package synta
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SuggestFix returns human readable suggestions on how to rename a filename
+// so that it conforms to the spec. The filename is split on separators and
+// compared segment by segment with the variant of the spec having the same
+// number of segments, so the suggestions are only a heuristic. A conforming
+// filename yields no suggestions.
+func (s Synta) SuggestFix(filename string) (suggestions []string, err error) {
+	expr, err := s.BuildRegexp()
+	if err != nil {
+		return
+	}
+	if _, e := s.Extract(filename); e == nil {
+		return
+	}
+
+	name, ext, hasExt := filename, "", false
+	if dot := strings.LastIndex(filename, "."); dot >= 0 && s.Filename.HasExtension() {
+		name, ext, hasExt = filename[:dot], filename[dot+1:], true
+	}
+
+	extDef := Definition{Pattern: s.extensionSource()}
+	if !hasExt && s.Filename.HasExtension() {
+		suggestions = append(suggestions, fmt.Sprintf("add an extension matching `%s`", extDef.Source()))
+	} else if suggestion, ok := suggestValue(extDef, ext); hasExt && !ok {
+		suggestions = append(suggestions, "extension "+suggestion)
+	}
+
+	parts := strings.Split(name, s.separator())
+	var closest []Segment
+	for _, variant := range variants(s.Filename.Segments) {
+		if len(variant) == len(parts) {
+			closest = variant
+			break
+		}
+		if closest == nil || abs(len(variant)-len(parts)) < abs(len(closest)-len(parts)) {
+			closest = variant
+		}
+	}
+
+	if len(closest) != len(parts) {
+		suggestions = append(suggestions, fmt.Sprintf("expected %d segments separated by `%s`, found %d", len(closest), s.separator(), len(parts)))
+	} else {
+		for i, segment := range closest {
+			def, ok := s.segmentDefinition(segment)
+			if !ok {
+				continue
+			}
+			suggestion, ok := suggestValue(def, parts[i])
+			if ok {
+				continue
+			}
+			if segment.Kind == SegmentTypeInline {
+				suggestions = append(suggestions, fmt.Sprintf("segment %d %s", i+1, suggestion))
+			} else {
+				suggestions = append(suggestions, fmt.Sprintf("segment %d (`%s`) %s", i+1, *segment.Value, suggestion))
+			}
+		}
+	}
+
+	if len(suggestions) == 0 {
+		suggestions = append(suggestions, fmt.Sprintf("rename the file to match `%s`", expr.String()))
+	}
+	return
+}
+
+// suggestValue checks a value against a definition and, when it does not
+// match, returns a suggestion on how to fix it
+func suggestValue(def Definition, value string) (suggestion string, ok bool) {
+	if matches, _ := matchesDefinition(def, value); matches {
+		return "", true
+	}
+	if lower := strings.ToLower(value); lower != value {
+		if matches, _ := matchesDefinition(def, lower); matches {
+			return fmt.Sprintf("should be renamed from `%s` to `%s`", value, lower), false
+		}
+	}
+
+	suggestion = fmt.Sprintf("`%s` must match `%s`", value, def.Source())
+	if len(def.Comments) > 0 {
+		suggestion += " (" + def.Comments[0] + ")"
+	}
+	return suggestion, false
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
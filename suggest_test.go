@@ -0,0 +1,53 @@
+package synta
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const suggestInput = `name = [a-z]+
+; 8 digits
+date = [0-9]{8}
+tag = [a-z]+
+ext = pdf|txt
+> name-date(-tag)?.ext`
+
+func TestSuggestFixWithWrongExtensionCase(t *testing.T) {
+	synta := MustSynta(suggestInput)
+
+	suggestions, err := synta.SuggestFix("analisi-20240101.PDF")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"extension should be renamed from `PDF` to `pdf`"}, suggestions)
+}
+
+func TestSuggestFixWithWrongSegment(t *testing.T) {
+	synta := MustSynta(suggestInput)
+
+	suggestions, err := synta.SuggestFix("analisi-2024-esame.pdf")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"segment 2 (`date`) `2024` must match `[0-9]{8}` (8 digits)"}, suggestions)
+
+	suggestions, err = synta.SuggestFix("Analisi-20240101")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		"add an extension matching `pdf|txt`",
+		"segment 1 (`name`) should be renamed from `Analisi` to `analisi`",
+	}, suggestions)
+}
+
+func TestSuggestFixWithWrongSegmentCount(t *testing.T) {
+	synta := MustSynta(suggestInput)
+
+	suggestions, err := synta.SuggestFix("analisi.pdf")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"expected 2 segments separated by `-`, found 1"}, suggestions)
+}
+
+func TestSuggestFixWithConformingFilename(t *testing.T) {
+	synta := MustSynta(suggestInput)
+
+	suggestions, err := synta.SuggestFix("analisi-20240101-esame.pdf")
+	assert.Nil(t, err)
+	assert.Empty(t, suggestions)
+}
@@ -0,0 +1,98 @@
+package synta
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// maxListedExtensions is the maximum number of extensions Summary lists
+// explicitly, instead of naming the extension's identifier
+const maxListedExtensions = 5
+
+// Summary returns a description of the spec aimed at end users: the
+// structure of the filename in prose, followed by a bullet list describing
+// each field through the comments of its definition.
+func (s Synta) Summary() (summary string) {
+	parts := describeSegments(s.Filename.Segments)
+	prose := parts[len(parts)-1]
+	if len(parts) > 1 {
+		prose = strings.Join(parts[:len(parts)-1], ", ") + ", then " + prose
+	}
+
+	ext := s.Filename.formatExtension()
+	if extensions, ok := finiteStrings(s.extensionSource(), maxListedExtensions); ok {
+		ext = strings.Join(extensions, " or ")
+	}
+	summary = fmt.Sprintf("A filename is made of %s, with extension %s.\n", prose, ext)
+	if !s.Filename.HasExtension() {
+		summary = fmt.Sprintf("A filename is made of %s, without extension.\n", prose)
+	}
+
+	if len(s.Filename.Comments) > 0 {
+		summary += "\n" + strings.Join(s.Filename.Comments, "\n") + "\n"
+	}
+
+	summary += "\n"
+	seen := map[Identifier]bool{}
+	for _, id := range s.Filename.identifiers() {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		def := s.Definitions[id]
+		comments := def.Comments
+		if slices.Contains(s.Filename.extensions(), id) && len(s.Filename.ExtensionComments) > 0 {
+			comments = s.Filename.ExtensionComments
+		}
+		summary += fmt.Sprintf("- %s (`%s`)", id, def.Source())
+		if len(comments) > 0 {
+			summary += ": " + strings.Join(comments, " ")
+		}
+		summary += "\n"
+	}
+	return
+}
+
+// describeSegments describes each segment with a short noun phrase
+func describeSegments(segments []Segment) (parts []string) {
+	for _, segment := range segments {
+		switch segment.Kind {
+		case SegmentTypeIdentifier:
+			parts = append(parts, article(string(*segment.Value))+" "+string(*segment.Value))
+		case SegmentTypeBackreference:
+			parts = append(parts, "the same "+string(*segment.Value)+" again")
+		case SegmentTypeInline:
+			parts = append(parts, "text matching `"+segment.Inline.Source()+"`")
+		case SegmentTypeLiteral:
+			parts = append(parts, "the text `"+string(*segment.Value)+"`")
+		case SegmentTypeRepeat:
+			inner := describeSegments(segment.Subsegments)
+			if len(inner) == 1 && strings.HasPrefix(inner[0], "a") {
+				_, noun, _ := strings.Cut(inner[0], " ")
+				parts = append(parts, "a repeated "+noun)
+			} else {
+				parts = append(parts, "a repeated group of "+strings.Join(inner, " followed by "))
+			}
+		case SegmentTypeAlternation:
+			parts = append(parts, "either "+strings.Join(describeSegments(segment.Subsegments), " or "))
+		case SegmentTypeOptional:
+			inner := describeSegments(segment.Subsegments)
+			if len(inner) == 1 && strings.HasPrefix(inner[0], "a") {
+				_, noun, _ := strings.Cut(inner[0], " ")
+				parts = append(parts, "an optional "+noun)
+			} else {
+				parts = append(parts, "an optional group of "+strings.Join(inner, " followed by "))
+			}
+		}
+	}
+	return
+}
+
+func article(word string) string {
+	if strings.ContainsAny(word[:1], "aeiou") {
+		return "an"
+	}
+	return "a"
+}
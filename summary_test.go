@@ -0,0 +1,54 @@
+package synta
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummary(t *testing.T) {
+	synta := MustSynta(`; the name of the course
+name = [a-z]+
+; a short tag, such as
+; the author
+tag = [a-z]+
+author = [a-z]+
+; the date of the exam
+date = [0-9]{8}
+ext = pdf|txt
+; the exams of a course
+> name(-tag)?(-author(-tag)?)?-date.ext ; the format of the document`)
+
+	expected := "A filename is made of a name, an optional tag, an optional group of an author followed by an optional tag, then a date, with extension pdf or txt.\n" +
+		"\n" +
+		"the exams of a course\n" +
+		"\n" +
+		"- name (`[a-z]+`): the name of the course\n" +
+		"- tag (`[a-z]+`): a short tag, such as the author\n" +
+		"- author (`[a-z]+`)\n" +
+		"- date (`[0-9]{8}`): the date of the exam\n" +
+		"- ext (`pdf|txt`): the format of the document\n"
+	assert.Equal(t, expected, synta.Summary())
+}
+
+func TestSummaryWithBackreference(t *testing.T) {
+	synta := MustSynta(`code = [A-Z]{3}
+ext = [a-z]+
+> code-=code.ext`)
+
+	expected := "A filename is made of a code, then the same code again, with extension ext.\n" +
+		"\n" +
+		"- code (`[A-Z]{3}`)\n" +
+		"- ext (`[a-z]+`)\n"
+	assert.Equal(t, expected, synta.Summary())
+}
+
+func TestSummaryWithAlternation(t *testing.T) {
+	synta := MustSynta(`lesson = lesson
+lab = lab
+number = [0-9]{2}
+ext = pdf
+> (lesson|lab)-number.ext`)
+	assert.True(t, strings.HasPrefix(synta.Summary(), "A filename is made of either a lesson or a lab, then a number, with extension pdf.\n"))
+}
@@ -0,0 +1,327 @@
+package synta
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"slices"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+)
+
+// OrderPolicy describes how definitions are expected to be ordered in a file
+type OrderPolicy uint
+
+const (
+	// OrderAny accepts definitions in any order
+	OrderAny OrderPolicy = iota
+	// OrderAlphabetical expects definitions sorted by identifier
+	OrderAlphabetical
+	// OrderOfUse expects definitions in the order they are first used by the
+	// filename, extension included. Unused definitions are not checked.
+	OrderOfUse
+)
+
+// ValidateOptions selects the checks run by Validate
+type ValidateOptions struct {
+	Order OrderPolicy
+	// Reserved reports the definitions whose identifier is a keyword or the
+	// name of a builtin definition
+	Reserved bool
+	// KnownExtensions, when set, reports the extensions accepted by the spec
+	// which are not part of it
+	KnownExtensions map[string]bool
+}
+
+// A Warning is a problem found in a spec which does not prevent it from
+// being parsed, but is likely to be a mistake or a style violation
+type Warning struct {
+	Identifier Identifier
+	Message    string
+}
+
+func (w Warning) String() string {
+	if w.Identifier == "" {
+		return w.Message
+	}
+	return fmt.Sprintf("`%s`: %s", w.Identifier, w.Message)
+}
+
+// Validate runs the checks selected by the options on the spec, its
+// alternative filenames included, and returns the warnings found
+func (s Synta) Validate(opts ValidateOptions) (warnings []Warning) {
+	if w, ok := s.checkOrder(opts.Order); !ok {
+		warnings = append(warnings, w)
+	}
+	for i, f := range s.filenames() {
+		for _, w := range checkOptionalsCapture(f.Segments, "") {
+			if i > 0 {
+				w.Message += fmt.Sprintf(", in the alternative filename `%s`", f)
+			}
+			warnings = append(warnings, w)
+		}
+	}
+	warnings = append(warnings, s.checkAnchors()...)
+	warnings = append(warnings, s.checkAdjacent()...)
+	if opts.Reserved {
+		warnings = append(warnings, s.checkReserved()...)
+	}
+	if opts.KnownExtensions != nil {
+		warnings = append(warnings, s.checkExtensions(opts.KnownExtensions)...)
+	}
+	return
+}
+
+// checkAnchors reports the definitions whose regexp contains anchors, in
+// alphabetical order
+func (s Synta) checkAnchors() (warnings []Warning) {
+	ids := []string{}
+	for id := range s.Definitions {
+		ids = append(ids, string(id))
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		if s.Definitions[Identifier(id)].HasAnchors() {
+			warnings = append(warnings, Warning{Identifier(id), "contains anchors, which are meaningless within a filename and should be removed"})
+		}
+	}
+	return
+}
+
+// checkAdjacent reports the segments whose regexp can match the separator
+// while another segment follows them, in any variant of the filename, since
+// Extract may then match part of the following value along with theirs.
+// Literal segments are skipped, as no separator surrounds them. The
+// neighbours are found walking the segments, rather than listing every
+// variant, whose number grows exponentially with the optional segments.
+func (s Synta) checkAdjacent() (warnings []Warning) {
+	sep := s.separator()
+	sepRune, _ := utf8.DecodeRuneInString(sep)
+	reported := map[[2]Identifier]bool{}
+	pairs := [][2]Segment{}
+	for _, f := range s.filenames() {
+		pairs = append(pairs, adjacentSegments(f.Segments).pairs...)
+	}
+	for _, pair := range pairs {
+		left, right := pair[0], pair[1]
+		if left.Kind == SegmentTypeLiteral || right.Kind == SegmentTypeLiteral {
+			continue
+		}
+		def, ok := s.segmentDefinition(left)
+		if !ok {
+			continue
+		}
+		re, err := syntax.Parse(def.Source(), syntax.Perl)
+		if err != nil || !canMatchRune(re, sepRune) {
+			continue
+		}
+
+		names := [2]Identifier{segmentName(left), segmentName(right)}
+		if reported[names] {
+			continue
+		}
+		reported[names] = true
+		warnings = append(warnings, Warning{names[0], fmt.Sprintf("can match the separator `%s` and may take part of `%s`, which follows it; consider excluding `%s` from its regexp", sep, names[1], sep)})
+	}
+	return
+}
+
+// adjacency describes the segments a sequence can start and end with, as in
+// its variants, whether it can be empty and which segments can follow each
+// other within it. Repeated segments count as a single segment.
+type adjacency struct {
+	first, last []Segment
+	empty       bool
+	pairs       [][2]Segment
+}
+
+// adjacentSegments walks the segments and finds which of them can be
+// neighbours, through optional segments and alternations
+func adjacentSegments(segments []Segment) (a adjacency) {
+	a.empty = true
+	for _, segment := range segments {
+		var inner adjacency
+		switch segment.Kind {
+		case SegmentTypeOptional:
+			inner = adjacentSegments(segment.Subsegments)
+			inner.empty = true
+		case SegmentTypeAlternation:
+			for _, branch := range segment.Subsegments {
+				b := adjacentSegments([]Segment{branch})
+				inner.first = append(inner.first, b.first...)
+				inner.last = append(inner.last, b.last...)
+				inner.empty = inner.empty || b.empty
+				inner.pairs = append(inner.pairs, b.pairs...)
+			}
+		default:
+			inner = adjacency{first: []Segment{segment}, last: []Segment{segment}}
+		}
+
+		a.pairs = append(a.pairs, inner.pairs...)
+		for _, left := range a.last {
+			for _, right := range inner.first {
+				a.pairs = append(a.pairs, [2]Segment{left, right})
+			}
+		}
+		if a.empty {
+			a.first = append(a.first, inner.first...)
+		}
+		if inner.empty {
+			a.last = append(a.last, inner.last...)
+		} else {
+			a.last = slices.Clone(inner.last)
+		}
+		a.empty = a.empty && inner.empty
+	}
+	return
+}
+
+// segmentName names a segment which is neither optional nor an alternation in
+// warnings
+func segmentName(segment Segment) Identifier {
+	if segment.Kind == SegmentTypeIdentifier || segment.Kind == SegmentTypeBackreference {
+		return *segment.Value
+	}
+	return Identifier(formatSegments([]Segment{segment}))
+}
+
+// DefinitionsWithUnboundedPatterns returns the definitions, in declaration
+// order, whose regexp can match the empty string, the separator of the spec
+// or `.`, and may then swallow part of the values around them. A definition
+// whose minimum length is set is not reported for matching the empty string.
+func (s Synta) DefinitionsWithUnboundedPatterns() (unbounded []Identifier) {
+	sepRune, _ := utf8.DecodeRuneInString(s.separator())
+	unbounded = []Identifier{}
+	for _, id := range s.declarationOrder() {
+		def := s.Definitions[id]
+		re, err := syntax.Parse(def.Source(), syntax.Perl)
+		if err != nil {
+			continue
+		}
+		empty := def.MinLen == 0 && regexp.MustCompile("^(?:"+def.Source()+")$").MatchString("")
+		if empty || canMatchRune(re, sepRune) || canMatchRune(re, '.') {
+			unbounded = append(unbounded, id)
+		}
+	}
+	return
+}
+
+// canMatchRune tells whether the regexp contains a literal or a character
+// class matching the rune
+func canMatchRune(re *syntax.Regexp, r rune) bool {
+	switch re.Op {
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return true
+	case syntax.OpLiteral:
+		return slices.Contains(re.Rune, r)
+	case syntax.OpCharClass:
+		for i := 0; i+1 < len(re.Rune); i += 2 {
+			if re.Rune[i] <= r && r <= re.Rune[i+1] {
+				return true
+			}
+		}
+		return false
+	}
+	for _, sub := range re.Sub {
+		if canMatchRune(sub, r) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkReserved reports the definitions declared by the file, builtins
+// excluded, whose identifier is reserved
+func (s Synta) checkReserved() (warnings []Warning) {
+	for _, node := range s.Nodes {
+		if node.Type != NodeTypeDefinition {
+			continue
+		}
+		if reason, reserved := reservation(node.Identifier); reserved {
+			warnings = append(warnings, Warning{node.Identifier, reason})
+		}
+	}
+	return
+}
+
+// checkExtensions reports the extensions accepted by the spec which are not
+// known, or a single warning when they cannot be listed
+func (s Synta) checkExtensions(known map[string]bool) (warnings []Warning) {
+	extensions := s.Extensions()
+	if extensions == nil {
+		return []Warning{{s.Filename.Extension, "accepts too many extensions to check them against the known ones"}}
+	}
+	for _, ext := range extensions {
+		if !known[string(ext)] {
+			warnings = append(warnings, Warning{s.Filename.Extension, fmt.Sprintf("accepts `%s`, which is not a known extension", ext)})
+		}
+	}
+	return
+}
+
+// checkOptionalsCapture reports the optional segments which do not contain
+// any identifier, as Extract cannot tell whether they are present or not.
+// Positions are 1-based and dot separated for nested segments.
+func checkOptionalsCapture(segments []Segment, prefix string) (warnings []Warning) {
+	for i, segment := range segments {
+		if segment.Kind != SegmentTypeOptional {
+			continue
+		}
+
+		position := prefix + strconv.Itoa(i+1)
+		if len(getAllIdentifiers(segment.Subsegments)) == 0 {
+			warnings = append(warnings, Warning{"", fmt.Sprintf("optional segment at position %s has no identifier, its presence cannot be reported", position)})
+		} else {
+			warnings = append(warnings, checkOptionalsCapture(segment.Subsegments, position+".")...)
+		}
+	}
+	return
+}
+
+// checkOrder reports the first definition, in declaration order, which does
+// not respect the given policy
+func (s Synta) checkOrder(policy OrderPolicy) (w Warning, ok bool) {
+	rank := map[Identifier]int{}
+	switch policy {
+	case OrderAny:
+		return w, true
+	case OrderOfUse:
+		for _, id := range s.AllIdentifiers() {
+			if _, seen := rank[id]; !seen {
+				rank[id] = len(rank)
+			}
+		}
+	}
+
+	var previous *Identifier
+	for _, node := range s.Nodes {
+		if node.Type != NodeTypeDefinition {
+			continue
+		}
+		id := node.Identifier
+
+		if policy == OrderOfUse {
+			if _, used := rank[id]; !used {
+				continue
+			}
+		}
+
+		if previous != nil {
+			outOfOrder := false
+			switch policy {
+			case OrderAlphabetical:
+				outOfOrder = id < *previous
+			case OrderOfUse:
+				outOfOrder = rank[id] < rank[*previous]
+			}
+			if outOfOrder {
+				w = Warning{id, fmt.Sprintf("should be declared before `%s`", *previous)}
+				return w, false
+			}
+		}
+		previous = &id
+	}
+	return w, true
+}
@@ -0,0 +1,184 @@
+package synta
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const unorderedInput = `name = [a-z]+
+ext = pdf
+date = [0-9]{8}
+unused = a|b
+> date-name.ext`
+
+func TestValidateOrderAny(t *testing.T) {
+	assert.Empty(t, MustSynta(unorderedInput).Validate(ValidateOptions{}))
+}
+
+func TestValidateOrderAlphabetical(t *testing.T) {
+	warnings := MustSynta(unorderedInput).Validate(ValidateOptions{Order: OrderAlphabetical})
+	assert.Equal(t, []Warning{{"ext", "should be declared before `name`"}}, warnings)
+
+	sorted := `date = [0-9]{8}
+ext = pdf
+name = [a-z]+
+> date-name.ext`
+	assert.Empty(t, MustSynta(sorted).Validate(ValidateOptions{Order: OrderAlphabetical}))
+}
+
+func TestValidateOrderOfUse(t *testing.T) {
+	warnings := MustSynta(unorderedInput).Validate(ValidateOptions{Order: OrderOfUse})
+	assert.Equal(t, []Warning{{"date", "should be declared before `ext`"}}, warnings)
+	assert.Equal(t, "`date`: should be declared before `ext`", warnings[0].String())
+
+	ordered := `date = [0-9]{8}
+unused = a|b
+name = [a-z]+
+ext = pdf
+> date-name.ext`
+	assert.Empty(t, MustSynta(ordered).Validate(ValidateOptions{Order: OrderOfUse}))
+}
+
+func TestValidateOptionalsWithoutIdentifiers(t *testing.T) {
+	synta := MustSynta(`name = [a-z]+
+ext = pdf
+> name(-name(-name)?)?.ext`)
+	assert.Empty(t, synta.Validate(ValidateOptions{}))
+
+	// the grammar cannot express such optionals yet, so build them by hand
+	nested := synta.Filename.Segments[1].Subsegments
+	nested[1].Subsegments = nil
+	synta.Filename.Segments = append(synta.Filename.Segments, Segment{SegmentTypeOptional, nil, nil, nil})
+
+	assert.Equal(t, []Warning{
+		{"", "optional segment at position 2.2 has no identifier, its presence cannot be reported"},
+		{"", "optional segment at position 3 has no identifier, its presence cannot be reported"},
+	}, synta.Validate(ValidateOptions{}))
+}
+
+func TestValidateReserved(t *testing.T) {
+	input := `date = [0-9]{6}
+unless = [a-z]+
+name = [a-z]+
+> date-name(-unless)?-year.ext`
+	synta, err := ParseSyntaWithOptions(input, Options{Builtins: true})
+	assert.Nil(t, err)
+
+	assert.Empty(t, synta.Validate(ValidateOptions{}))
+	assert.Equal(t, []Warning{
+		{"date", "shadows the builtin definition"},
+		{"unless", "is a reserved keyword"},
+	}, synta.Validate(ValidateOptions{Reserved: true}))
+
+	synta = MustSynta("separator = [a-z]+\nformat = pdf\n> separator.format")
+	assert.Equal(t, []Warning{{"separator", "is a reserved keyword"}}, synta.Validate(ValidateOptions{Reserved: true}))
+}
+
+func TestValidateAnchors(t *testing.T) {
+	synta := MustSynta(`name = ^foo$
+tag = a|b
+ext = pdf
+> name(-tag)?.ext`)
+	assert.Equal(t, []Warning{
+		{"name", "contains anchors, which are meaningless within a filename and should be removed"},
+	}, synta.Validate(ValidateOptions{}))
+}
+
+func TestValidateKnownExtensions(t *testing.T) {
+	known := map[string]bool{"pdf": true, "txt": true}
+	synta := MustSynta(`name = [a-z]+
+ext = pdf|pdff|txt
+> name.ext`)
+	assert.Empty(t, synta.Validate(ValidateOptions{}))
+	assert.Equal(t, []Warning{
+		{"ext", "accepts `pdff`, which is not a known extension"},
+	}, synta.Validate(ValidateOptions{KnownExtensions: known}))
+
+	synta = MustSynta(`name = [a-z]+
+ext = [a-z]+
+> name.ext`)
+	assert.Equal(t, []Warning{
+		{"ext", "accepts too many extensions to check them against the known ones"},
+	}, synta.Validate(ValidateOptions{KnownExtensions: known}))
+
+	synta = MustSynta(`name = [a-z]+
+ext = pdf|txt
+> name.ext`)
+	assert.Empty(t, synta.Validate(ValidateOptions{KnownExtensions: known}))
+}
+
+func TestValidateAdjacentSeparator(t *testing.T) {
+	synta := MustSynta(`name = [a-z-]+
+tag = [a-z]+
+year = [0-9]{4}
+ext = pdf
+> name(-tag)?-year.ext`)
+	assert.Equal(t, []Warning{
+		{"name", "can match the separator `-` and may take part of `tag`, which follows it; consider excluding `-` from its regexp"},
+		{"name", "can match the separator `-` and may take part of `year`, which follows it; consider excluding `-` from its regexp"},
+	}, synta.Validate(ValidateOptions{}))
+
+	synta = MustSynta(`name = [a-z]+
+year = [0-9]{4}
+ext = pdf
+> year-name-{.+}.ext`)
+	assert.Empty(t, synta.Validate(ValidateOptions{}))
+
+	synta = MustSynta(`name = [a-z_-]+
+year = [0-9]{4}
+ext = pdf
+! separator = _
+> name-year.ext`)
+	assert.Equal(t, []Warning{
+		{"name", "can match the separator `_` and may take part of `year`, which follows it; consider excluding `_` from its regexp"},
+	}, synta.Validate(ValidateOptions{}))
+}
+
+func TestValidateAdjacentSeparatorThroughOptionals(t *testing.T) {
+	synta := MustSynta(`name = [a-z-]+
+tag = [a-z]+
+year = [0-9]{4}
+ext = pdf
+> (-tag)?-name(-year)?(-{v[0-9]}|-tag)?.ext`)
+	assert.Equal(t, []Warning{
+		{"name", "can match the separator `-` and may take part of `year`, which follows it; consider excluding `-` from its regexp"},
+		{"name", "can match the separator `-` and may take part of `{v[0-9]}`, which follows it; consider excluding `-` from its regexp"},
+		{"name", "can match the separator `-` and may take part of `tag`, which follows it; consider excluding `-` from its regexp"},
+	}, synta.Validate(ValidateOptions{}))
+
+	// the variants are not listed, so many optional segments are checked
+	// quickly
+	synta = MustSynta("name = [a-z-]+\next = pdf\n> name" + strings.Repeat("(-name)?", 64) + ".ext")
+	assert.Len(t, synta.Validate(ValidateOptions{}), 1)
+}
+
+func TestDefinitionsWithUnboundedPatterns(t *testing.T) {
+	synta := MustSynta(`name = [a-z-]+
+num = [0-9]*
+short = [0-9]* len(1,4)
+version = [0-9.]+
+year = [0-9]{4}
+ext = pdf
+> name-num-short-version-year.ext`)
+	assert.Equal(t, []Identifier{"name", "num", "version"}, synta.DefinitionsWithUnboundedPatterns())
+
+	synta = MustSynta(`year = [0-9]{4}
+ext = pdf
+> year.ext`)
+	assert.Empty(t, synta.DefinitionsWithUnboundedPatterns())
+}
+
+func TestValidateWithAlternatives(t *testing.T) {
+	synta, err := ParseSyntaWithOptions(`name = [a-z-]+
+year = [0-9]{4}
+ext = pdf
+> name.ext
+> name-year(-{v1})?.ext`, Options{MultipleFilenames: true})
+	assert.Nil(t, err)
+	assert.Equal(t, []Warning{
+		{"", "optional segment at position 3 has no identifier, its presence cannot be reported, in the alternative filename `name-year(-{v1})?.ext`"},
+		{"name", "can match the separator `-` and may take part of `year`, which follows it; consider excluding `-` from its regexp"},
+	}, synta.Validate(ValidateOptions{}))
+}
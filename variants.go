@@ -0,0 +1,190 @@
+package synta
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// variants returns every sequence of segments the filename can expand to, by
+// choosing whether each optional segment is present or not, and a branch of
+// each alternation. Variants where an optional segment is absent come first.
+// Variants contain no optional segments nor alternations, while repeated
+// segments are kept, with one variant of their own segments each.
+func variants(segments []Segment) [][]Segment {
+	return firstVariants(segments, math.MaxInt)
+}
+
+// firstVariants returns the first variants of the segments, as listed by
+// variants, stopping once limit of them are found
+func firstVariants(segments []Segment, limit int) (result [][]Segment) {
+	result = [][]Segment{{}}
+	for _, segment := range segments {
+		next := [][]Segment{}
+		switch segment.Kind {
+		case SegmentTypeOptional:
+			inner := firstVariants(segment.Subsegments, limit)
+			for _, variant := range result {
+				next = append(next, variant)
+				for _, in := range inner {
+					next = append(next, append(variant[:len(variant):len(variant)], in...))
+				}
+			}
+		case SegmentTypeRepeat:
+			inner := firstVariants(segment.Subsegments, limit)
+			for _, variant := range result {
+				for _, in := range inner {
+					repeat := Segment{Kind: SegmentTypeRepeat, Subsegments: in}
+					next = append(next, append(variant[:len(variant):len(variant)], repeat))
+				}
+			}
+		case SegmentTypeAlternation:
+			for _, variant := range result {
+				for _, branch := range segment.Subsegments {
+					next = append(next, append(variant[:len(variant):len(variant)], branch))
+				}
+			}
+		default:
+			for _, variant := range result {
+				next = append(next, append(variant[:len(variant):len(variant)], segment))
+			}
+		}
+		// every variant extends to at least one, so the first ones only
+		// derive from the first ones found so far
+		result = next[:min(len(next), limit)]
+	}
+	return
+}
+
+// countVariants returns the number of variants of the segments without
+// listing them, saturating at math.MaxInt
+func countVariants(segments []Segment) (count int) {
+	count = 1
+	for _, segment := range segments {
+		n := 1
+		switch segment.Kind {
+		case SegmentTypeOptional:
+			n = countVariants(segment.Subsegments)
+			if n < math.MaxInt {
+				n++
+			}
+		case SegmentTypeRepeat:
+			n = countVariants(segment.Subsegments)
+		case SegmentTypeAlternation:
+			n = len(segment.Subsegments)
+		}
+		if count > math.MaxInt/n {
+			return math.MaxInt
+		}
+		count *= n
+	}
+	return
+}
+
+// maxTableVariants bounds the number of variants listed by VariantTable
+const maxTableVariants = 32
+
+// VariantTable lists every shape of filename accepted by the spec, one per
+// line, next to a generated example. Only the first maxTableVariants variants
+// are listed, followed by a line counting the others.
+func (s Synta) VariantTable() string {
+	rows := [][2]string{{"Variant", "Example"}}
+	for _, variant := range firstVariants(s.Filename.Segments, maxTableVariants) {
+		example, err := generator{}.generateSegments(s, variant)
+		if err == nil && s.Filename.HasExtension() {
+			var ext string
+			ext, err = generator{}.generateDefinition(s, s.Filename.Extension)
+			example += "." + ext
+		}
+		if err != nil {
+			example = "(none)"
+		}
+		rows = append(rows, [2]string{Filename{Segments: variant, Extension: s.Filename.Extension, Extensions: s.Filename.Extensions}.String(), example})
+	}
+
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row[0]))
+	}
+	table := ""
+	for _, row := range rows {
+		table += fmt.Sprintf("%-*s  %s\n", width, row[0], row[1])
+	}
+	if count := countVariants(s.Filename.Segments); count > maxTableVariants {
+		table += fmt.Sprintf("... and %d more variants\n", count-maxTableVariants)
+	}
+	return table
+}
+
+// segmentDefinition returns the definition matched by a segment which is not
+// optional, that is its inline pattern, the escaped text of a literal or the
+// definition of its identifier. Repeated segments have no definition.
+func (s Synta) segmentDefinition(segment Segment) (def Definition, ok bool) {
+	switch segment.Kind {
+	case SegmentTypeInline:
+		return *segment.Inline, true
+	case SegmentTypeLiteral:
+		return Definition{Pattern: regexp.QuoteMeta(string(*segment.Value))}, true
+	case SegmentTypeRepeat:
+		return
+	}
+	def, ok = s.Definitions[*segment.Value]
+	return
+}
+
+// variantRegexp builds the anchored regexp matching exactly the given variant
+// of the filename, in the same fashion as BuildRegexp
+func (s Synta) variantRegexp(variant []Segment) (expr *regexp.Regexp, names map[string]Identifier, err error) {
+	groups := newGroupNamer()
+	pattern, err := s.variantPattern(variant, groups)
+	if err != nil {
+		return
+	}
+
+	ext, err := s.extensionPattern(func(id Identifier, def Definition) string {
+		return "(?P<" + groups.name(id) + ">" + def.Source() + ")"
+	})
+	if err != nil {
+		return
+	}
+
+	expr, err = regexp.Compile("^" + pattern + ext + "$")
+	names = groups.names
+	return
+}
+
+// variantPattern joins the patterns of the segments of a variant with the
+// separator, except around literals. A repeated segment matches its own
+// segments at least once.
+func (s Synta) variantPattern(variant []Segment, groups *groupNamer) (pattern string, err error) {
+	sep := regexp.QuoteMeta(s.separator())
+	parts := []string{}
+	for i, segment := range variant {
+		if i > 0 && segment.Kind != SegmentTypeLiteral && variant[i-1].Kind != SegmentTypeLiteral {
+			parts = append(parts, sep)
+		}
+		if segment.Kind == SegmentTypeRepeat {
+			inner, e := s.variantPattern(segment.Subsegments, groups)
+			if e != nil {
+				err = e
+				return
+			}
+			parts = append(parts, "(?:"+inner+")(?:"+sep+"(?:"+inner+"))*")
+			continue
+		}
+
+		def, ok := s.segmentDefinition(segment)
+		if !ok {
+			err = fmt.Errorf("missing definition for `%s`", *segment.Value)
+			return
+		}
+		if segment.Kind == SegmentTypeInline || segment.Kind == SegmentTypeLiteral {
+			parts = append(parts, "(?:"+def.Source()+")")
+		} else {
+			parts = append(parts, "(?P<"+groups.name(*segment.Value)+">"+def.Source()+")")
+		}
+	}
+	pattern = strings.Join(parts, "")
+	return
+}
@@ -0,0 +1,56 @@
+package synta
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVariants(t *testing.T) {
+	synta := MustSynta(`a = a
+b = b
+c = c
+> a(-b(-c)?)?(-c)?.a`)
+
+	identifiers := [][]Identifier{}
+	for _, variant := range variants(synta.Filename.Segments) {
+		identifiers = append(identifiers, getAllIdentifiers(variant))
+	}
+	assert.Equal(t, [][]Identifier{
+		{"a"},
+		{"a", "c"},
+		{"a", "b"},
+		{"a", "b", "c"},
+		{"a", "b", "c"},
+		{"a", "b", "c", "c"},
+	}, identifiers)
+
+	assert.Equal(t, 6, countVariants(synta.Filename.Segments))
+	assert.Equal(t, variants(synta.Filename.Segments)[:4], firstVariants(synta.Filename.Segments, 4))
+}
+
+func TestVariantTable(t *testing.T) {
+	synta := MustSynta(`name = [a-z]+
+tag = v[0-9]
+ext = pdf|txt
+> name(-tag)?.ext`)
+
+	expected := "Variant       Example\n" +
+		"name.ext      a.pdf\n" +
+		"name-tag.ext  a-v0.pdf\n"
+	assert.Equal(t, expected, synta.VariantTable())
+
+	synta = MustSynta(`a = a
+> a(-a)?(-a)?(-a)?(-a)?(-a)?(-a)?.a`)
+	lines := strings.Split(strings.TrimSuffix(synta.VariantTable(), "\n"), "\n")
+	assert.Len(t, lines, maxTableVariants+2)
+	assert.Equal(t, "... and 32 more variants", lines[len(lines)-1])
+
+	// only the listed variants are built
+	synta = MustSynta("a = a\n> a" + strings.Repeat("(-a)?", 40) + ".a")
+	lines = strings.Split(strings.TrimSuffix(synta.VariantTable(), "\n"), "\n")
+	assert.Len(t, lines, maxTableVariants+2)
+	assert.Equal(t, fmt.Sprintf("... and %d more variants", 1<<40-maxTableVariants), lines[len(lines)-1])
+}
@@ -0,0 +1,22 @@
+package synta
+
+// Walk calls fn for each node of the spec, in the order of the file, until fn
+// returns false
+func (s Synta) Walk(fn func(node Node) bool) {
+	for _, node := range s.Nodes {
+		if !fn(node) {
+			return
+		}
+	}
+}
+
+// WalkSegments calls fn for each segment depth-first, visiting a segment
+// before its subsegments. When fn returns false, the subsegments of the
+// segment are skipped, while the walk goes on with the following segments.
+func WalkSegments(segs []Segment, fn func(seg Segment) bool) {
+	for _, seg := range segs {
+		if fn(seg) {
+			WalkSegments(seg.Subsegments, fn)
+		}
+	}
+}
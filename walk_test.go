@@ -0,0 +1,51 @@
+package synta
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWalk(t *testing.T) {
+	synta := MustSynta(`name = [a-z]+
+> name(-tag)?.ext
+tag = [a-z]+
+ext = pdf`)
+
+	visited := []Identifier{}
+	synta.Walk(func(node Node) bool {
+		visited = append(visited, node.Identifier)
+		return true
+	})
+	assert.Equal(t, []Identifier{"name", "", "tag", "ext"}, visited)
+
+	visited = []Identifier{}
+	synta.Walk(func(node Node) bool {
+		visited = append(visited, node.Identifier)
+		return node.Type != NodeTypeFilename
+	})
+	assert.Equal(t, []Identifier{"name", ""}, visited)
+}
+
+func TestWalkSegments(t *testing.T) {
+	synta := MustSynta(`name = [a-z]+
+tag = [a-z]+
+author = [a-z]+
+ext = pdf
+> name(-tag(-author)?)?-{[0-9]+}.ext`)
+
+	visited := []string{}
+	WalkSegments(synta.Filename.Segments, func(seg Segment) bool {
+		visited = append(visited, formatSegments([]Segment{seg}))
+		return true
+	})
+	assert.Equal(t, []string{"name", "(-tag(-author)?)?", "tag", "(-author)?", "author", "{[0-9]+}"}, visited)
+
+	// the subsegments of the outer optional are skipped, not the segments following it
+	visited = []string{}
+	WalkSegments(synta.Filename.Segments, func(seg Segment) bool {
+		visited = append(visited, formatSegments([]Segment{seg}))
+		return seg.Kind != SegmentTypeOptional
+	})
+	assert.Equal(t, []string{"name", "(-tag(-author)?)?", "{[0-9]+}"}, visited)
+}